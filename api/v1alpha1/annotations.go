@@ -0,0 +1,52 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+const (
+	// ManagedKeysAnnotation is stamped on nodes with the comma separated
+	// taint keys the controller has removed from them.
+	ManagedKeysAnnotation = "taint-remover.peppy-ratio.dev/managed-keys"
+	// HistoryAnnotation is stamped on nodes with a JSON list of the most
+	// recent taint removals and their times, oldest first.
+	HistoryAnnotation = "taint-remover.peppy-ratio.dev/history"
+	// LastPatchAnnotation is stamped on nodes with a JSON object recording
+	// the version of the controller that last patched them and when.
+	LastPatchAnnotation = "taint-remover.peppy-ratio.dev/last-patch"
+	// MatchModeAnnotation selects how the taints listed in a TaintRemover
+	// match the node taints: exact, prefix, key-only or regex. The taints
+	// match exactly when it is absent or invalid.
+	MatchModeAnnotation = "taint-remover.peppy-ratio.dev/match-mode"
+	// EffectOnlyAnnotation opts a TaintRemover in to effect-only taints when
+	// set to "true": a listed taint with an empty key matches every node
+	// taint with its effect. Such taints are invalid without it.
+	EffectOnlyAnnotation = "taint-remover.peppy-ratio.dev/effect-only"
+	// LastActorAnnotation is stamped on TaintRemovers with a JSON object
+	// identifying the controller instance that last swept with them: its
+	// version and pod name.
+	LastActorAnnotation = "taint-remover.peppy-ratio.dev/last-actor"
+	// ConfirmedAnnotation blesses a TaintRemover when set to "true". With
+	// --require-remover-annotation, the removers without it are ignored.
+	ConfirmedAnnotation = "taint-remover.peppy-ratio.dev/confirmed"
+)
@@ -35,10 +35,163 @@ import (
 // TaintRemoverSpec defines the desired state of TaintRemover
 type TaintRemoverSpec struct {
 	Taints []corev1.Taint `json:"taints,omitempty"`
+	// TaintKeyEffects lists taint keys to remove with several effects. Each
+	// entry removes the taints with the key and any of the listed effects.
+	TaintKeyEffects []TaintKeyEffects `json:"taintKeyEffects,omitempty"`
+	// Sources lists the taint key prefixes the remover is allowed to remove.
+	// When empty, matching taints are removed regardless of their key prefix.
+	Sources []string `json:"sources,omitempty"`
+	// WhenConditionFalse lists node conditions that must be False or absent
+	// on a node before the taints are removed from it.
+	WhenConditionFalse []corev1.NodeConditionType `json:"whenConditionFalse,omitempty"`
+	// RemovalDelay is the time to wait after a matching taint is first
+	// observed on a node before removing it.
+	RemovalDelay *metav1.Duration `json:"removalDelay,omitempty"`
+	// NodeGroup restricts the remover to the nodes whose node group
+	// annotation, e.g. karpenter.sh/nodepool, has this value. All node groups
+	// are targeted when it is empty.
+	NodeGroup string `json:"nodeGroup,omitempty"`
+	// MachineOwner restricts the remover to the nodes created by Cluster API
+	// for the owner, e.g. a MachineDeployment, whose name is in the machine
+	// owner annotation, cluster.x-k8s.io/owner-name by default. All nodes
+	// are targeted when it is empty.
+	MachineOwner string `json:"machineOwner,omitempty"`
+	// NodeSelector restricts the remover to the nodes matching the selector.
+	// All nodes are targeted when it is not specified.
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	// OSSelector restricts the remover to the nodes whose kubernetes.io/os
+	// label has this value, for OS-specific taints. It adds to NodeSelector.
+	// +kubebuilder:validation:Enum=linux;windows
+	OSSelector string `json:"osSelector,omitempty"`
+	// RemoveAll removes every taint from the nodes selected by NodeSelector
+	// and OSSelector, except the taints managed by Kubernetes itself. It has
+	// no effect without either.
+	RemoveAll bool `json:"removeAll,omitempty"`
+	// ExcludeEffects lists the taint effects that are never removed, even
+	// when the taint matches.
+	ExcludeEffects []corev1.TaintEffect `json:"excludeEffects,omitempty"`
+	// KeySelector selects the taints to remove by key. A taint is removed
+	// when it is listed in Taints or selected by KeySelector.
+	KeySelector *TaintKeySelector `json:"keySelector,omitempty"`
+	// MatchExpression selects the taints to remove with a CEL expression,
+	// evaluated for each taint of a node with the variables taint (key,
+	// value and effect) and node (name and labels), e.g.
+	// taint.key.startsWith("example.com/") && node.labels["pool"] == "gpu".
+	// A taint is removed when the expression is true, as well as when it is
+	// listed in Taints or selected by KeySelector.
+	MatchExpression string `json:"matchExpression,omitempty"`
+	// AggressivePreferNoSchedule removes PreferNoSchedule taints without
+	// waiting for RemovalDelay, as removing them rarely disrupts workloads.
+	AggressivePreferNoSchedule bool `json:"aggressivePreferNoSchedule,omitempty"`
+	// Priority orders the removals among removers. Taints of removers with
+	// a higher priority are processed first.
+	Priority int32 `json:"priority,omitempty"`
+	// WaitForWorkload references a Deployment that must be Available before
+	// the taints are removed.
+	WaitForWorkload *WorkloadReference `json:"waitForWorkload,omitempty"`
+	// MaintenanceWindow references a ConfigMap describing a maintenance
+	// window shared by several removers. The taints are removed only while
+	// the window is active.
+	MaintenanceWindow *MaintenanceWindowReference `json:"maintenanceWindow,omitempty"`
+	// ObserveOnly computes the removals without performing them. The taints
+	// that would be removed are previewed in the status.
+	ObserveOnly bool `json:"observeOnly,omitempty"`
+	// Uncordon also marks the nodes schedulable in the patch removing their
+	// taints, which completes their re-activation in one step.
+	Uncordon bool `json:"uncordon,omitempty"`
+	// MaxNodes limits the number of nodes the remover patches in a single
+	// reconcile, including the node events until the next one. The remaining
+	// nodes are handled by the following reconciles. The number is unlimited
+	// when it is not specified.
+	// +kubebuilder:validation:Minimum=1
+	MaxNodes *int32 `json:"maxNodes,omitempty"`
+}
+
+// WorkloadReference references a Deployment by namespace and name.
+type WorkloadReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// MaintenanceWindowReference references a ConfigMap by namespace and name.
+// The window is active from the RFC 3339 time in its "start" key until the
+// one in its "end" key. A missing key leaves that side of the window open.
+type MaintenanceWindowReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// TaintKeyEffects matches the taints with a key and any of several effects.
+type TaintKeyEffects struct {
+	Key string `json:"key"`
+	// +kubebuilder:validation:MinItems=1
+	Effects []corev1.TaintEffect `json:"effects"`
+}
+
+// TaintKeySelector selects taints by key. A key is selected when it matches
+// any of the keys, prefixes or patterns.
+type TaintKeySelector struct {
+	// MatchKeys lists the taint keys to select.
+	MatchKeys []string `json:"matchKeys,omitempty"`
+	// MatchPrefixes lists the taint key prefixes to select.
+	MatchPrefixes []string `json:"matchPrefixes,omitempty"`
+	// MatchPatterns lists the taint key patterns to select. A pattern has a
+	// single '*', either trailing as in "example.com/*" or leading as in
+	// "*/uninitialized".
+	MatchPatterns []string `json:"matchPatterns,omitempty"`
+}
+
+// NoExecuteRemoval describes the removal of a NoExecute taint from a node.
+type NoExecuteRemoval struct {
+	Node  string       `json:"node"`
+	Taint corev1.Taint `json:"taint"`
+}
+
+// NodeDiff previews the taints that would be removed from a node.
+type NodeDiff struct {
+	Node string `json:"node"`
+	// Diff lists the taints that would be removed, one "-<taint>" per line.
+	Diff string `json:"diff"`
+}
+
+// TaintOutcome is the outcome of the last sweep for a taint.
+// +kubebuilder:validation:Enum=Removed;NotPresent;Failed
+type TaintOutcome string
+
+const (
+	// TaintOutcomeRemoved means the taint was removed from a node.
+	TaintOutcomeRemoved TaintOutcome = "Removed"
+	// TaintOutcomeNotPresent means no node has had the taint to remove since
+	// it was listed.
+	TaintOutcomeNotPresent TaintOutcome = "NotPresent"
+	// TaintOutcomeFailed means patching a node to remove the taint failed.
+	TaintOutcomeFailed TaintOutcome = "Failed"
+)
+
+// TaintStatus describes the last outcome for a taint listed in Taints.
+type TaintStatus struct {
+	Key     string             `json:"key"`
+	Effect  corev1.TaintEffect `json:"effect,omitempty"`
+	Outcome TaintOutcome       `json:"outcome"`
+	// LastTransitionTime is the last time the outcome changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
 }
 
 // TaintRemoverStatus defines the observed state of TaintRemover
 type TaintRemoverStatus struct {
+	// NoExecuteRemovals lists the NoExecute taint removals the controller is
+	// about to perform. They are performed only when the controller runs
+	// with --confirm-noexecute.
+	NoExecuteRemovals []NoExecuteRemoval `json:"noExecuteRemovals,omitempty"`
+	// TaintStatuses lists the last outcome for each taint in Taints.
+	TaintStatuses []TaintStatus `json:"taintStatuses,omitempty"`
+	// PreviewDiffs previews the removals of an ObserveOnly remover for a
+	// limited number of nodes.
+	PreviewDiffs []NodeDiff `json:"previewDiffs,omitempty"`
+	// RemovalCounts counts the removals of each taint in Taints, keyed by
+	// key:effect, across sweeps. A removal from several nodes counts once
+	// per node.
+	RemovalCounts map[string]int32 `json:"removalCounts,omitempty"`
 }
 
 //+kubebuilder:object:root=true
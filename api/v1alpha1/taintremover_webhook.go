@@ -0,0 +1,227 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/norseto/taint-remover/internal/celmatch"
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// log is for logging in this package.
+var taintremoverlog = logf.Log.WithName("taintremover-resource")
+
+// SetupWebhookWithManager will setup the manager to manage the webhooks.
+// The TaintRemovers are validated by the validator, which allows any effect
+// when nil.
+func (r *TaintRemover) SetupWebhookWithManager(mgr ctrl.Manager, validator *TaintRemoverValidator) error {
+	if validator == nil {
+		validator = &TaintRemoverValidator{}
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(validator).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-nodes-peppy-ratio-dev-v1alpha1-taintremover,mutating=true,failurePolicy=fail,sideEffects=None,groups=nodes.peppy-ratio.dev,resources=taintremovers,verbs=create;update,versions=v1alpha1,name=mtaintremover.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &TaintRemover{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+// It normalizes the taints and the keys and effects of TaintKeyEffects so
+// that typos in effect casing or surrounding whitespace do not silently
+// prevent them from matching. It has no side effects, so server-side dry runs
+// are defaulted the same way.
+func (r *TaintRemover) Default() {
+	taintremoverlog.Info("default", "name", r.Name)
+
+	for i := range r.Spec.Taints {
+		r.Spec.Taints[i] = tutil.NormalizeTaint(r.Spec.Taints[i])
+	}
+	for i := range r.Spec.TaintKeyEffects {
+		ke := &r.Spec.TaintKeyEffects[i]
+		ke.Key = tutil.NormalizeTaint(corev1.Taint{Key: ke.Key}).Key
+		for j := range ke.Effects {
+			ke.Effects[j] = tutil.NormalizeTaint(corev1.Taint{Effect: ke.Effects[j]}).Effect
+		}
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-nodes-peppy-ratio-dev-v1alpha1-taintremover,mutating=false,failurePolicy=fail,sideEffects=None,groups=nodes.peppy-ratio.dev,resources=taintremovers,verbs=create;update,versions=v1alpha1,name=vtaintremover.kb.io,admissionReviewVersions=v1
+
+// allEffects are the taint effects a remover can target.
+var allEffects = []corev1.TaintEffect{
+	corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute,
+}
+
+// TaintRemoverValidator validates the TaintRemovers against the policy of
+// the cluster.
+type TaintRemoverValidator struct {
+	// AllowedEffects lists the taint effects the TaintRemovers may target,
+	// so that e.g. NoExecute removers are rejected cluster-wide. Any effect
+	// is allowed when empty.
+	AllowedEffects []corev1.TaintEffect
+}
+
+var _ webhook.CustomValidator = &TaintRemoverValidator{}
+
+// ValidateCreate implements webhook.CustomValidator.
+func (v *TaintRemoverValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(obj)
+}
+
+// ValidateUpdate implements webhook.CustomValidator.
+func (v *TaintRemoverValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, v.validate(newObj)
+}
+
+// ValidateDelete implements webhook.CustomValidator. Deletions are always
+// allowed.
+func (v *TaintRemoverValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *TaintRemoverValidator) validate(obj runtime.Object) error {
+	r, ok := obj.(*TaintRemover)
+	if !ok {
+		return fmt.Errorf("expected a TaintRemover but got a %T", obj)
+	}
+	taintremoverlog.Info("validate", "name", r.Name)
+
+	errs := validateTaints(r)
+	errs = append(errs, v.validateEffects(r)...)
+	if len(errs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("TaintRemover").GroupKind(), r.Name, errs)
+	}
+	return nil
+}
+
+// validateTaints checks the keys, values and effects of the listed taints
+// against the limits of Kubernetes. The keys of the prefix and regex match
+// modes are prefixes and patterns rather than taint keys, and are checked
+// when the remover is processed. An effect-only taint only has its effect
+// checked. The match expression must compile.
+func validateTaints(r *TaintRemover) field.ErrorList {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+	mode := r.Annotations[MatchModeAnnotation]
+	effectOnly := r.Annotations[EffectOnlyAnnotation] == "true"
+	for i, t := range r.Spec.Taints {
+		path := spec.Child("taints").Index(i)
+		var err error
+		switch {
+		case effectOnly && t.Key == "" && t.Effect != "":
+			err = tutil.ValidateTaintEffect(t.Effect)
+		case mode == "prefix" || mode == "regex":
+			continue
+		default:
+			err = tutil.CheckTaintValidation(t)
+		}
+		if err != nil {
+			errs = append(errs, field.Invalid(path, t.ToString(), err.Error()))
+		}
+	}
+	for i, ke := range r.Spec.TaintKeyEffects {
+		path := spec.Child("taintKeyEffects").Index(i)
+		if err := tutil.CheckTaintValidation(corev1.Taint{Key: ke.Key}); err != nil {
+			errs = append(errs, field.Invalid(path.Child("key"), ke.Key, err.Error()))
+		}
+		for j, effect := range ke.Effects {
+			if err := tutil.ValidateTaintEffect(effect); err != nil {
+				errs = append(errs, field.Invalid(path.Child("effects").Index(j), effect, err.Error()))
+			}
+		}
+	}
+	if r.Spec.MatchExpression != "" {
+		if _, err := celmatch.Compile(r.Spec.MatchExpression); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("matchExpression"), r.Spec.MatchExpression, err.Error()))
+		}
+	}
+	return errs
+}
+
+// validateEffects checks that the remover only targets allowed effects. A
+// listed taint targets its effect, or any effect when it has none or is
+// matched by key only. RemoveAll, KeySelector and MatchExpression target any
+// effect. The
+// effects in ExcludeEffects are never targeted.
+func (v *TaintRemoverValidator) validateEffects(r *TaintRemover) field.ErrorList {
+	if len(v.AllowedEffects) == 0 {
+		return nil
+	}
+	allowed := sets.New(v.AllowedEffects...)
+	excluded := sets.New(r.Spec.ExcludeEffects...)
+	var supported []string
+	for _, effect := range v.AllowedEffects {
+		supported = append(supported, string(effect))
+	}
+
+	var errs field.ErrorList
+	check := func(path *field.Path, effects ...corev1.TaintEffect) {
+		for _, effect := range effects {
+			if !allowed.Has(effect) && !excluded.Has(effect) {
+				errs = append(errs, field.NotSupported(path, effect, supported))
+			}
+		}
+	}
+	spec := field.NewPath("spec")
+	keyOnly := r.Annotations[MatchModeAnnotation] == "key-only"
+	for i, t := range r.Spec.Taints {
+		path := spec.Child("taints").Index(i).Child("effect")
+		if t.Effect == "" || keyOnly {
+			check(path, allEffects...)
+		} else {
+			check(path, t.Effect)
+		}
+	}
+	for i, ke := range r.Spec.TaintKeyEffects {
+		for j, effect := range ke.Effects {
+			check(spec.Child("taintKeyEffects").Index(i).Child("effects").Index(j), effect)
+		}
+	}
+	if r.Spec.RemoveAll {
+		check(spec.Child("removeAll"), allEffects...)
+	}
+	if r.Spec.KeySelector != nil {
+		check(spec.Child("keySelector"), allEffects...)
+	}
+	if r.Spec.MatchExpression != "" {
+		check(spec.Child("matchExpression"), allEffects...)
+	}
+	return errs
+}
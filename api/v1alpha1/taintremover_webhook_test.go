@@ -0,0 +1,195 @@
+package v1alpha1
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("TaintRemover Webhook", func() {
+	Context("When creating a TaintRemover", func() {
+		It("should normalize the taints", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "normalized-taint-remover"},
+				Spec: TaintRemoverSpec{
+					Taints: []corev1.Taint{{Key: " foo ", Value: "bar ", Effect: "noschedule"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tr)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, tr)).To(Succeed())
+			}()
+
+			stored := &TaintRemover{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: tr.Name}, stored)).To(Succeed())
+			Expect(stored.Spec.Taints).To(Equal([]corev1.Taint{
+				{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoSchedule},
+			}))
+		})
+
+		It("should normalize the keys and effects of TaintKeyEffects", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "normalized-key-effects-taint-remover"},
+				Spec: TaintRemoverSpec{
+					TaintKeyEffects: []TaintKeyEffects{
+						{Key: " foo ", Effects: []corev1.TaintEffect{"noschedule", " NOEXECUTE"}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tr, client.DryRunAll)).To(Succeed())
+			Expect(tr.Spec.TaintKeyEffects).To(Equal([]TaintKeyEffects{
+				{Key: "foo", Effects: []corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectNoExecute}},
+			}))
+		})
+
+		It("should normalize the taints without storing them on a server-side dry run", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "dry-run-taint-remover"},
+				Spec: TaintRemoverSpec{
+					Taints: []corev1.Taint{{Key: " foo ", Effect: "noexecute"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tr, client.DryRunAll)).To(Succeed())
+			Expect(tr.Spec.Taints).To(Equal([]corev1.Taint{
+				{Key: "foo", Effect: corev1.TaintEffectNoExecute},
+			}))
+
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: tr.Name}, &TaintRemover{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("When validating the taints", func() {
+		It("should reject a taint with an over-long key", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "long-key-taint-remover"},
+				Spec: TaintRemoverSpec{
+					Taints: []corev1.Taint{{Key: "example.com/" + strings.Repeat("a", 64), Effect: corev1.TaintEffectNoSchedule}},
+				},
+			}
+			err := k8sClient.Create(ctx, tr, client.DryRunAll)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.taints[0]"))
+		})
+
+		It("should reject a taint key with an invalid prefix", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "bad-prefix-taint-remover"},
+				Spec: TaintRemoverSpec{
+					TaintKeyEffects: []TaintKeyEffects{
+						{Key: "Example_COM/foo", Effects: []corev1.TaintEffect{corev1.TaintEffectNoSchedule}},
+					},
+				},
+			}
+			err := k8sClient.Create(ctx, tr, client.DryRunAll)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.taintKeyEffects[0].key"))
+		})
+
+		It("should admit the key prefixes of the prefix match mode", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "prefix-mode-taint-remover",
+					Annotations: map[string]string{MatchModeAnnotation: "prefix"},
+				},
+				Spec: TaintRemoverSpec{
+					Taints: []corev1.Taint{{Key: "example.com/", Effect: corev1.TaintEffectNoSchedule}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tr, client.DryRunAll)).To(Succeed())
+		})
+	})
+
+	Context("When validating the match expression", func() {
+		It("should admit valid expressions", func() {
+			for _, expression := range []string{
+				`taint.key.startsWith("example.com/")`,
+				`node.labels["pool"] == "gpu" && taint.effect == "NoSchedule"`,
+				`taint.value.matches("^v[0-9]+$") || "spot" in node.labels`,
+			} {
+				tr := &TaintRemover{
+					ObjectMeta: metav1.ObjectMeta{Name: "expression-taint-remover"},
+					Spec:       TaintRemoverSpec{MatchExpression: expression},
+				}
+				Expect(k8sClient.Create(ctx, tr, client.DryRunAll)).To(Succeed(), expression)
+			}
+		})
+
+		It("should reject an invalid expression", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "bad-expression-taint-remover"},
+				Spec:       TaintRemoverSpec{MatchExpression: `taint.key == 1`},
+			}
+			err := k8sClient.Create(ctx, tr, client.DryRunAll)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.matchExpression"))
+		})
+	})
+
+	Context("When allowing only some effects", func() {
+		BeforeEach(func() {
+			validator.AllowedEffects = []corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule}
+			DeferCleanup(func() {
+				validator.AllowedEffects = nil
+			})
+		})
+
+		It("should admit a TaintRemover targeting allowed effects", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "allowed-effects-taint-remover"},
+				Spec: TaintRemoverSpec{
+					Taints: []corev1.Taint{{Key: "foo", Effect: corev1.TaintEffectNoSchedule}},
+					TaintKeyEffects: []TaintKeyEffects{
+						{Key: "bar", Effects: []corev1.TaintEffect{corev1.TaintEffectPreferNoSchedule}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, tr)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, tr)).To(Succeed())
+		})
+
+		It("should reject a TaintRemover targeting a disallowed effect", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "disallowed-effects-taint-remover"},
+				Spec: TaintRemoverSpec{
+					Taints: []corev1.Taint{{Key: "foo", Effect: corev1.TaintEffectNoExecute}},
+				},
+			}
+			err := k8sClient.Create(ctx, tr)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.taints[0].effect"))
+		})
+
+		It("should reject a match expression TaintRemover targeting every effect", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "expression-effects-taint-remover"},
+				Spec:       TaintRemoverSpec{MatchExpression: `taint.effect == "NoSchedule"`},
+			}
+			err := k8sClient.Create(ctx, tr, client.DryRunAll)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.matchExpression"))
+		})
+
+		It("should admit a RemoveAll TaintRemover excluding the disallowed effects", func() {
+			tr := &TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "remove-all-taint-remover"},
+				Spec: TaintRemoverSpec{
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"example.com/pool": "a"}},
+					RemoveAll:    true,
+				},
+			}
+			err := k8sClient.Create(ctx, tr, client.DryRunAll)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+
+			tr.Spec.ExcludeEffects = []corev1.TaintEffect{corev1.TaintEffectNoExecute}
+			Expect(k8sClient.Create(ctx, tr, client.DryRunAll)).To(Succeed())
+		})
+	})
+})
@@ -30,16 +30,113 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindowReference) DeepCopyInto(out *MaintenanceWindowReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindowReference.
+func (in *MaintenanceWindowReference) DeepCopy() *MaintenanceWindowReference {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindowReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NoExecuteRemoval) DeepCopyInto(out *NoExecuteRemoval) {
+	*out = *in
+	in.Taint.DeepCopyInto(&out.Taint)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NoExecuteRemoval.
+func (in *NoExecuteRemoval) DeepCopy() *NoExecuteRemoval {
+	if in == nil {
+		return nil
+	}
+	out := new(NoExecuteRemoval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDiff) DeepCopyInto(out *NodeDiff) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDiff.
+func (in *NodeDiff) DeepCopy() *NodeDiff {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDiff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaintKeyEffects) DeepCopyInto(out *TaintKeyEffects) {
+	*out = *in
+	if in.Effects != nil {
+		in, out := &in.Effects, &out.Effects
+		*out = make([]v1.TaintEffect, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaintKeyEffects.
+func (in *TaintKeyEffects) DeepCopy() *TaintKeyEffects {
+	if in == nil {
+		return nil
+	}
+	out := new(TaintKeyEffects)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaintKeySelector) DeepCopyInto(out *TaintKeySelector) {
+	*out = *in
+	if in.MatchKeys != nil {
+		in, out := &in.MatchKeys, &out.MatchKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MatchPrefixes != nil {
+		in, out := &in.MatchPrefixes, &out.MatchPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MatchPatterns != nil {
+		in, out := &in.MatchPatterns, &out.MatchPatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaintKeySelector.
+func (in *TaintKeySelector) DeepCopy() *TaintKeySelector {
+	if in == nil {
+		return nil
+	}
+	out := new(TaintKeySelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaintRemover) DeepCopyInto(out *TaintRemover) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaintRemover.
@@ -102,6 +199,58 @@ func (in *TaintRemoverSpec) DeepCopyInto(out *TaintRemoverSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TaintKeyEffects != nil {
+		in, out := &in.TaintKeyEffects, &out.TaintKeyEffects
+		*out = make([]TaintKeyEffects, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WhenConditionFalse != nil {
+		in, out := &in.WhenConditionFalse, &out.WhenConditionFalse
+		*out = make([]v1.NodeConditionType, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemovalDelay != nil {
+		in, out := &in.RemovalDelay, &out.RemovalDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludeEffects != nil {
+		in, out := &in.ExcludeEffects, &out.ExcludeEffects
+		*out = make([]v1.TaintEffect, len(*in))
+		copy(*out, *in)
+	}
+	if in.KeySelector != nil {
+		in, out := &in.KeySelector, &out.KeySelector
+		*out = new(TaintKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WaitForWorkload != nil {
+		in, out := &in.WaitForWorkload, &out.WaitForWorkload
+		*out = new(WorkloadReference)
+		**out = **in
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindowReference)
+		**out = **in
+	}
+	if in.MaxNodes != nil {
+		in, out := &in.MaxNodes, &out.MaxNodes
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaintRemoverSpec.
@@ -117,6 +266,32 @@ func (in *TaintRemoverSpec) DeepCopy() *TaintRemoverSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaintRemoverStatus) DeepCopyInto(out *TaintRemoverStatus) {
 	*out = *in
+	if in.NoExecuteRemovals != nil {
+		in, out := &in.NoExecuteRemovals, &out.NoExecuteRemovals
+		*out = make([]NoExecuteRemoval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TaintStatuses != nil {
+		in, out := &in.TaintStatuses, &out.TaintStatuses
+		*out = make([]TaintStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreviewDiffs != nil {
+		in, out := &in.PreviewDiffs, &out.PreviewDiffs
+		*out = make([]NodeDiff, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemovalCounts != nil {
+		in, out := &in.RemovalCounts, &out.RemovalCounts
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaintRemoverStatus.
@@ -128,3 +303,34 @@ func (in *TaintRemoverStatus) DeepCopy() *TaintRemoverStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaintStatus) DeepCopyInto(out *TaintStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaintStatus.
+func (in *TaintStatus) DeepCopy() *TaintStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TaintStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadReference) DeepCopyInto(out *WorkloadReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadReference.
+func (in *WorkloadReference) DeepCopy() *WorkloadReference {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadReference)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,101 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// parseGenerateArgs parses the remover name and the taints of generate.
+func parseGenerateArgs(fs *flag.FlagSet, args []string) (string, []corev1.Taint, error) {
+	nameFlag := fs.String("name", "", "The name of the generated TaintRemover.")
+	taintsFlag := fs.String("taints", "", "Comma separated taints to remove, e.g. key:NoSchedule,key=value:NoExecute.")
+	if err := fs.Parse(args); err != nil {
+		return "", nil, err
+	}
+	if *nameFlag == "" {
+		return "", nil, fmt.Errorf("--name is required")
+	}
+	if errs := validation.IsDNS1123Subdomain(*nameFlag); len(errs) > 0 {
+		return "", nil, fmt.Errorf("invalid name %q: %s", *nameFlag, strings.Join(errs, "; "))
+	}
+	if *taintsFlag == "" {
+		return "", nil, fmt.Errorf("--taints is required")
+	}
+	taints, _, err := tutil.ParseTaints(strings.Split(*taintsFlag, ","))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, t := range taints {
+		if err := tutil.CheckTaintValidation(t); err != nil {
+			return "", nil, fmt.Errorf("invalid taint %s: %w", t.ToString(), err)
+		}
+	}
+	return *nameFlag, taints, nil
+}
+
+// generateManifest returns the YAML manifest of a TaintRemover removing the
+// given taints.
+func generateManifest(name string, taints []corev1.Taint) ([]byte, error) {
+	remover := &nodesv1alpha1.TaintRemover{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: nodesv1alpha1.GroupVersion.String(),
+			Kind:       "TaintRemover",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: taints},
+	}
+	return yaml.Marshal(remover)
+}
+
+// generate prints a TaintRemover manifest removing the given taints.
+func generate(args []string) int {
+	name, taints, err := parseGenerateArgs(flag.NewFlagSet("generate", flag.ExitOnError), args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	data, err := generateManifest(name, taints)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unable to generate manifest:", err)
+		return 1
+	}
+	if _, err := os.Stdout.Write(data); err != nil {
+		fmt.Fprintln(os.Stderr, "unable to write manifest:", err)
+		return 1
+	}
+	return 0
+}
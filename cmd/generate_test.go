@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+func TestParseGenerateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "valid", args: []string{"--name", "mytr", "--taints", "foo:NoSchedule,bar=baz:NoExecute"}},
+		{name: "missing name", args: []string{"--taints=foo:NoSchedule"}, wantErr: true},
+		{name: "invalid name", args: []string{"--name=My_TR", "--taints=foo:NoSchedule"}, wantErr: true},
+		{name: "missing taints", args: []string{"--name=mytr"}, wantErr: true},
+		{name: "invalid effect", args: []string{"--name=mytr", "--taints=foo:Bogus"}, wantErr: true},
+		{name: "missing effect", args: []string{"--name=mytr", "--taints=foo"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+			name, _, err := parseGenerateArgs(fs, test.args)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseGenerateArgs() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !test.wantErr && name != "mytr" {
+				t.Errorf("parseGenerateArgs() name = %q, want %q", name, "mytr")
+			}
+		})
+	}
+}
+
+func TestGenerateManifestRoundTrip(t *testing.T) {
+	specs := []string{"foo:NoSchedule", "example.com/bar=baz:NoExecute", "qux:PreferNoSchedule"}
+	taints, _, err := tutil.ParseTaints(specs)
+	if err != nil {
+		t.Fatalf("ParseTaints() error = %v", err)
+	}
+
+	data, err := generateManifest("mytr", taints)
+	if err != nil {
+		t.Fatalf("generateManifest() error = %v", err)
+	}
+	remover := &nodesv1alpha1.TaintRemover{}
+	if err := yaml.UnmarshalStrict(data, remover); err != nil {
+		t.Fatalf("unable to read generated manifest: %v\n%s", err, data)
+	}
+	if remover.APIVersion != nodesv1alpha1.GroupVersion.String() || remover.Kind != "TaintRemover" {
+		t.Errorf("generated manifest type = %s/%s", remover.APIVersion, remover.Kind)
+	}
+	if remover.Name != "mytr" {
+		t.Errorf("generated manifest name = %q, want %q", remover.Name, "mytr")
+	}
+
+	var got []string
+	for _, taint := range remover.Spec.Taints {
+		got = append(got, taint.ToString())
+	}
+	parsed, _, err := tutil.ParseTaints(got)
+	if err != nil {
+		t.Fatalf("ParseTaints(%v) error = %v", got, err)
+	}
+	if !reflect.DeepEqual(parsed, taints) {
+		t.Errorf("round-tripped taints = %v, want %v", parsed, taints)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package main
+
+import (
+	"net/http"
+
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// applyLogLevel makes the log level adjustable at runtime, starting from the
+// level of the zap options: debug in development mode and info otherwise
+// unless --zap-log-level is set.
+func (o *options) applyLogLevel() {
+	level := zapcore.InfoLevel
+	if o.zapOpts.Development {
+		level = zapcore.DebugLevel
+	}
+	if o.zapOpts.Level != nil {
+		level = zapcore.LevelOf(o.zapOpts.Level)
+	}
+	o.logLevel = uberzap.NewAtomicLevelAt(level)
+	o.zapOpts.Level = o.logLevel
+}
+
+// logLevelHandler returns the handler of the log level. GET reports the
+// current level and PUT changes it, e.g. with {"level":"debug"}.
+func (o *options) logLevelHandler() http.Handler {
+	return o.logLevel
+}
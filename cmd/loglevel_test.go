@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func TestApplyLogLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		zapOpts zap.Options
+		want    zapcore.Level
+	}{
+		{name: "production", want: zapcore.InfoLevel},
+		{name: "development", zapOpts: zap.Options{Development: true}, want: zapcore.DebugLevel},
+		{name: "flag", zapOpts: zap.Options{Development: true, Level: zapcore.ErrorLevel}, want: zapcore.ErrorLevel},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := &options{zapOpts: test.zapOpts}
+			o.applyLogLevel()
+			if got := o.logLevel.Level(); got != test.want {
+				t.Errorf("log level = %v, want %v", got, test.want)
+			}
+			if o.zapOpts.Level != o.logLevel {
+				t.Error("zap options do not use the atomic level")
+			}
+		})
+	}
+}
+
+func TestLogLevelHandler(t *testing.T) {
+	o := &options{}
+	o.applyLogLevel()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	o.logLevelHandler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT /loglevel = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	if got := o.logLevel.Level(); got != zapcore.DebugLevel {
+		t.Errorf("log level = %v, want %v", got, zapcore.DebugLevel)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"loud"}`))
+	o.logLevelHandler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("PUT /loglevel with an invalid level = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if got := o.logLevel.Level(); got != zapcore.DebugLevel {
+		t.Errorf("log level = %v, want %v", got, zapcore.DebugLevel)
+	}
+}
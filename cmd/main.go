@@ -25,17 +25,29 @@ SOFTWARE.
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	uberzap "go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
@@ -43,9 +55,16 @@ import (
 	taintremover "github.com/norseto/taint-remover"
 	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
 	"github.com/norseto/taint-remover/internal/controller"
+	"github.com/norseto/taint-remover/internal/crd"
+	"github.com/norseto/taint-remover/internal/rbac"
+	tutil "github.com/norseto/taint-remover/internal/taints"
 	//+kubebuilder:scaffold:imports
 )
 
+// crdPollInterval is the interval at which the TaintRemover CRD is looked up
+// with --wait-for-crd.
+const crdPollInterval = 2 * time.Second
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -58,31 +77,160 @@ func init() {
 	//+kubebuilder:scaffold:scheme
 }
 
-func main() {
-	var metricsAddr string
-	var enableLeaderElection bool
-	var probeAddr string
-	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
-	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
-	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
+// options holds the command line options of the controller.
+type options struct {
+	metricsAddr          string
+	probeAddr            string
+	adminAddr            string
+	enableLeaderElection bool
+	skipRBACCheck        bool
+	patchTimeout         time.Duration
+	logAffectedPods      bool
+	ignoreNamespaces     string
+	onlyManageOwn        bool
+	confirmNoExecute     bool
+	protectNoExecute     bool
+	respectPDB           bool
+	taintsFromEnv        bool
+	decodeValues         bool
+	requireConfirmation  bool
+	webhookEffects       string
+	caseInsensitiveKeys  bool
+	allowedTaintKeys     string
+	maxPreviewNodes      int
+	maxHeartbeatStale    time.Duration
+	removerLabelSelector string
+	once                 bool
+	logFormat            string
+	cacheSyncPeriod      time.Duration
+	cacheSyncTimeout     time.Duration
+	reconcileJitter      time.Duration
+	emptyResultRequeue   time.Duration
+	nodeGroupKey         string
+	machineOwnerKey      string
+	maxPatchBytes        int
+	contestThreshold     int
+	contestTTL           time.Duration
+	nodeCooldown         time.Duration
+	disableNodeWatch     bool
+	waitForCRD           time.Duration
+	warnUnmatchedTaints  bool
+	removerDeletionEvent bool
+	maxRuntime           time.Duration
+	zapOpts              zap.Options
+	logLevel             uberzap.AtomicLevel
+}
+
+// bindFlags binds the options to the command line flags.
+func (o *options) bindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	fs.StringVar(&o.probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	fs.StringVar(&o.adminAddr, "admin-bind-address", "",
+		"The address the admin endpoints /status, /reconcile and /loglevel bind to. "+
+			"They are served read-only by the metrics server when empty, so that "+
+			"triggering a reconcile or changing the log level requires this address.")
+	fs.BoolVar(&o.enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
-	opts := zap.Options{
+	fs.BoolVar(&o.skipRBACCheck, "skip-rbac-check", false,
+		"Skip verifying the required RBAC permissions at startup.")
+	fs.DurationVar(&o.patchTimeout, "patch-timeout", 0,
+		"Timeout for patching a single node. Zero means no timeout.")
+	fs.BoolVar(&o.logAffectedPods, "log-affected-pods", false,
+		"Log the number of pods not tolerating a NoExecute taint before removing it.")
+	fs.StringVar(&o.ignoreNamespaces, "ignore-namespaces", "",
+		"Comma-separated namespaces whose pods are not counted as affected by NoExecute taint removals.")
+	fs.BoolVar(&o.onlyManageOwn, "only-manage-own", false,
+		"Only remove taints whose key is listed in the node's "+nodesv1alpha1.ManagedKeysAnnotation+" annotation.")
+	fs.BoolVar(&o.confirmNoExecute, "confirm-noexecute", false,
+		"Remove NoExecute taints. Without it they are only reported in the TaintRemover status.")
+	fs.BoolVar(&o.protectNoExecute, "protect-noexecute-with-pods", false,
+		"Keep the NoExecute taints of nodes hosting pods that do not tolerate them.")
+	fs.BoolVar(&o.respectPDB, "respect-pdb", false,
+		"Keep the NoExecute taints of nodes whose pods would exceed a PodDisruptionBudget, retrying later.")
+	fs.BoolVar(&o.taintsFromEnv, "taints-from-env", false,
+		"Also remove the comma or newline separated taints listed in the "+controller.TaintsEnvVar+
+			" environment variable. The TaintRemover CRD is not required then.")
+	fs.BoolVar(&o.decodeValues, "decode-values", false,
+		"URL-decode the values of the taints listed in the "+controller.TaintsEnvVar+
+			" environment variable, e.g. key=a%2Eb:NoSchedule for the value a.b. "+
+			"Decoded values must still be valid label values.")
+	fs.BoolVar(&o.requireConfirmation, "require-remover-annotation", false,
+		"Ignore the TaintRemovers not annotated with "+nodesv1alpha1.ConfirmedAnnotation+": \"true\", "+
+			"so that a new remover does not act until it is confirmed.")
+	fs.StringVar(&o.webhookEffects, "webhook-allowed-effects", "",
+		"Comma-separated taint effects the webhook allows the TaintRemovers to target, e.g. NoSchedule,PreferNoSchedule "+
+			"to reject NoExecute removers. Any effect is allowed when empty.")
+	fs.BoolVar(&o.caseInsensitiveKeys, "case-insensitive-keys", false,
+		"Match the taint keys listed in the TaintRemovers to the node taint keys ignoring case.")
+	fs.StringVar(&o.allowedTaintKeys, "allowed-taint-keys", "",
+		"Comma-separated taint keys the controller may remove, whatever the TaintRemovers specify. "+
+			"Any key may be removed when empty.")
+	fs.DurationVar(&o.maxHeartbeatStale, "max-heartbeat-staleness", 0,
+		"Skip the nodes whose last Ready heartbeat is older than the duration. Zero disables the check.")
+	fs.IntVar(&o.maxPreviewNodes, "max-preview-nodes", 10,
+		"The maximum number of nodes previewed in the status of an ObserveOnly TaintRemover.")
+	fs.StringVar(&o.removerLabelSelector, "remover-label-selector", "",
+		"Only process the TaintRemovers whose labels match the selector. All removers are processed when empty.")
+	fs.BoolVar(&o.once, "once", false,
+		"Run a single sweep over all nodes and exit instead of running the manager. "+
+			"The sweep reads from the API server directly rather than through a cache, "+
+			"and runs without leader election, events, metrics, health probes or webhooks.")
+	fs.DurationVar(&o.cacheSyncPeriod, "cache-sync-period", 0,
+		"The minimum interval at which watched resources are reconciled. "+
+			"Zero keeps the controller-runtime default.")
+	fs.BoolVar(&o.removerDeletionEvent, "remover-deletion-events", false,
+		"Emit an event when a TaintRemover is deleted, listing the taints no longer removed. "+
+			"The deletion is only logged otherwise.")
+	fs.BoolVar(&o.warnUnmatchedTaints, "warn-unmatched-taints", false,
+		"Log and emit a Warning event on the TaintRemovers listing taints that match no node after each sweep.")
+	fs.BoolVar(&o.disableNodeWatch, "disable-node-watch", false,
+		"Do not watch the nodes. Taints are then only removed on TaintRemover changes and periodic resyncs.")
+	fs.DurationVar(&o.waitForCRD, "wait-for-crd", 0,
+		"Wait up to the duration for the TaintRemover CRD to be established before starting. "+
+			"Zero does not wait.")
+	fs.DurationVar(&o.reconcileJitter, "reconcile-jitter", 0,
+		"Add a random delay of up to the duration to each requeued sweep. Zero adds no jitter.")
+	fs.StringVar(&o.nodeGroupKey, "nodegroup-annotation-key", "karpenter.sh/nodepool",
+		"The node annotation matched against the nodeGroup of the TaintRemovers, e.g. "+
+			"eks.amazonaws.com/nodegroup.")
+	fs.StringVar(&o.machineOwnerKey, "machine-owner-annotation-key", "cluster.x-k8s.io/owner-name",
+		"The node annotation matched against the machineOwner of the TaintRemovers.")
+	fs.IntVar(&o.maxPatchBytes, "max-patch-bytes", 0,
+		"Skip the nodes whose patch exceeds the size in bytes, as the API server would reject it, "+
+			"e.g. 3145728 for its default request limit. Zero does not check the size.")
+	fs.IntVar(&o.contestThreshold, "contest-threshold", 0,
+		"Stop removing a taint for a backoff once it was added back right after removal that many times "+
+			"in a row. Zero never stops.")
+	fs.DurationVar(&o.contestTTL, "contest-ttl", time.Minute,
+		"How soon a removed taint must be added back to count toward --contest-threshold. "+
+			"It is also the first backoff, doubled on each further re-addition.")
+	fs.DurationVar(&o.cacheSyncTimeout, "cache-sync-timeout", 30*time.Second,
+		"How long the first sweep waits for the cache to be synced. A sweep finding no tainted node "+
+			"before then is retried shortly. Zero waits indefinitely.")
+	fs.DurationVar(&o.nodeCooldown, "node-cooldown", 0,
+		"Do not patch a node again until the duration has elapsed since it was patched, e.g. while it flaps. "+
+			"Zero patches nodes whenever needed.")
+	fs.DurationVar(&o.emptyResultRequeue, "empty-result-requeue", 0,
+		"Sweep again after the duration when TaintRemovers exist but no node is tainted. "+
+			"Zero relies on the node events alone.")
+	fs.DurationVar(&o.maxRuntime, "max-runtime", 0,
+		"Stop the manager cleanly after the duration. Zero runs until terminated.")
+	fs.StringVar(&o.logFormat, "log-format", "",
+		"Log encoding, json or console. The zap options decide when empty.")
+	o.zapOpts = zap.Options{
 		Development: false,
 	}
-	opts.BindFlags(flag.CommandLine)
-	flag.Parse()
-
-	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
-
-	ctrl.Log.Info("Starting TaintRemover", "version", taintremover.RELEASE_VERSION,
-		"GitVersion", taintremover.GitVersion)
+	o.zapOpts.BindFlags(fs)
+}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+// managerOpts returns the manager options built from the command line options.
+func managerOpts(o *options) ctrl.Options {
+	opts := ctrl.Options{
 		Scheme:                 scheme,
-		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
+		Metrics:                metricsserver.Options{BindAddress: o.metricsAddr},
+		HealthProbeBindAddress: o.probeAddr,
+		LeaderElection:         o.enableLeaderElection,
 		LeaderElectionID:       "cab18bf0.peppy-ratio.dev",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
@@ -95,33 +243,244 @@ func main() {
 		// if you are doing or is intended to do any operation such as perform cleanups
 		// after the manager stops then its usage might be unsafe.
 		// LeaderElectionReleaseOnCancel: true,
-	})
+	}
+	if o.cacheSyncPeriod > 0 {
+		opts.Cache = cache.Options{SyncPeriod: &o.cacheSyncPeriod}
+	}
+	return opts
+}
+
+// applyLogFormat sets the log encoder from the log format, independently of
+// the development mode.
+func (o *options) applyLogFormat() error {
+	switch o.logFormat {
+	case "":
+	case "json":
+		zap.JSONEncoder()(&o.zapOpts)
+	case "console":
+		zap.ConsoleEncoder()(&o.zapOpts)
+	default:
+		return fmt.Errorf("invalid log format %q, must be json or console", o.logFormat)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping the empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseEffects parses a comma-separated list of taint effects.
+func parseEffects(s string) ([]corev1.TaintEffect, error) {
+	var effects []corev1.TaintEffect
+	for _, item := range splitList(s) {
+		effect := corev1.TaintEffect(item)
+		if err := tutil.ValidateTaintEffect(effect); err != nil {
+			return nil, err
+		}
+		effects = append(effects, effect)
+	}
+	return effects, nil
+}
+
+// addAdminHandlers serves the admin handlers on a dedicated server at addr,
+// or on the metrics server when addr is empty. The metrics server is not
+// meant for admin access, so it only serves the read-only requests.
+func addAdminHandlers(mgr ctrl.Manager, addr string, handlers map[string]http.Handler) error {
+	if addr != "" {
+		return mgr.Add(newAdminServer(addr, handlers))
+	}
+	for path, h := range handlers {
+		if err := mgr.AddMetricsServerExtraHandler(path, readOnly(h)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newReconciler returns a reconciler configured by the options.
+func newReconciler(o *options, c client.Client, apiReader client.Reader,
+	removerSelector labels.Selector) *controller.TaintRemoverReconciler {
+	return &controller.TaintRemoverReconciler{
+		Client:                    c,
+		PatchTimeout:              o.patchTimeout,
+		APIReader:                 apiReader,
+		LogAffectedPods:           o.logAffectedPods,
+		IgnoreNamespaces:          splitList(o.ignoreNamespaces),
+		OnlyManageOwn:             o.onlyManageOwn,
+		ConfirmNoExecute:          o.confirmNoExecute,
+		ProtectNoExecuteWithPods:  o.protectNoExecute,
+		RespectPDB:                o.respectPDB,
+		TaintsFromEnv:             o.taintsFromEnv,
+		DecodeTaintValues:         o.decodeValues,
+		RequireConfirmation:       o.requireConfirmation,
+		CaseInsensitiveKeys:       o.caseInsensitiveKeys,
+		AllowedTaintKeys:          splitList(o.allowedTaintKeys),
+		MaxPreviewNodes:           o.maxPreviewNodes,
+		MaxHeartbeatStaleness:     o.maxHeartbeatStale,
+		RemoverSelector:           removerSelector,
+		ReconcileJitter:           o.reconcileJitter,
+		EmptyResultRequeue:        o.emptyResultRequeue,
+		CacheSyncTimeout:          o.cacheSyncTimeout,
+		NodeGroupAnnotationKey:    o.nodeGroupKey,
+		MachineOwnerAnnotationKey: o.machineOwnerKey,
+		MaxPatchBytes:             o.maxPatchBytes,
+		ContestThreshold:          o.contestThreshold,
+		ContestTTL:                o.contestTTL,
+		NodeCooldown:              o.nodeCooldown,
+		DisableNodeWatch:          o.disableNodeWatch,
+		WarnUnmatchedTaints:       o.warnUnmatchedTaints,
+		RemoverDeletionEvents:     o.removerDeletionEvent,
+		PodName:                   os.Getenv("POD_NAME"),
+	}
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "dump-node-taints":
+			os.Exit(dumpNodeTaints(os.Args[2:]))
+		case "restore-node-taints":
+			os.Exit(restoreNodeTaints(os.Args[2:]))
+		case "remove-node":
+			os.Exit(removeNode(os.Args[2:]))
+		case "generate":
+			os.Exit(generate(os.Args[2:]))
+		case "validate":
+			os.Exit(validate(os.Args[2:]))
+		}
+	}
+
+	o := &options{}
+	o.bindFlags(flag.CommandLine)
+	flag.Parse()
+
+	os.Exit(run(o))
+}
+
+// run starts the controller manager and returns the exit code.
+func run(o *options) int {
+	if err := o.applyLogFormat(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	o.applyLogLevel()
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&o.zapOpts)))
+
+	ctrl.Log.Info("Starting TaintRemover", "version", taintremover.RELEASE_VERSION,
+		"GitVersion", taintremover.GitVersion)
+
+	config := ctrl.GetConfigOrDie()
+	if !o.skipRBACCheck {
+		clientset, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			setupLog.Error(err, "unable to create clientset")
+			return 1
+		}
+		err = rbac.CheckPermissions(context.Background(),
+			clientset.AuthorizationV1().SelfSubjectAccessReviews(), rbac.PermissionsFor(rbac.Options{
+				ListPods:   o.logAffectedPods || o.protectNoExecute,
+				RespectPDB: o.respectPDB,
+				WaitForCRD: o.waitForCRD > 0,
+			}))
+		if err != nil {
+			setupLog.Error(err, "insufficient RBAC permissions, grant them or use --skip-rbac-check")
+			return 1
+		}
+	}
+
+	if o.waitForCRD > 0 {
+		clientset, err := apiextensionsclient.NewForConfig(config)
+		if err != nil {
+			setupLog.Error(err, "unable to create apiextensions clientset")
+			return 1
+		}
+		setupLog.Info("Waiting for the TaintRemover CRD", "timeout", o.waitForCRD)
+		err = crd.WaitForEstablished(context.Background(), clientset.ApiextensionsV1().CustomResourceDefinitions(),
+			crd.TaintRemoverCRDName, crdPollInterval, o.waitForCRD)
+		if err != nil {
+			setupLog.Error(err, "TaintRemover CRD is not available")
+			return 1
+		}
+	}
+
+	removerSelector, err := labels.Parse(o.removerLabelSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid remover label selector")
+		return 1
+	}
+	allowedEffects, err := parseEffects(o.webhookEffects)
+	if err != nil {
+		setupLog.Error(err, "invalid webhook allowed effects")
+		return 1
+	}
+
+	if o.once {
+		// A single sweep does not need the manager: the cache would be synced
+		// for one read only, and the servers and elections it runs would stop
+		// right after.
+		c, err := client.New(config, client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create client")
+			return 1
+		}
+		return runOnce(ctrl.SetupSignalHandler(), newReconciler(o, c, c, removerSelector))
+	}
+
+	mgr, err := ctrl.NewManager(config, managerOpts(o))
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
-		os.Exit(1)
+		return 1
 	}
 
-	if err = (&controller.TaintRemoverReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	reconciler := newReconciler(o, mgr.GetClient(), mgr.GetAPIReader(), removerSelector)
+	reconciler.Scheme = mgr.GetScheme()
+	reconciler.Cache = mgr.GetCache()
+	reconciler.Recorder = mgr.GetEventRecorderFor("taint-remover")
+	if o.enableLeaderElection {
+		reconciler.Elected = mgr.Elected()
+	}
+	if err = reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "TaintRemover")
-		os.Exit(1)
+		return 1
+	}
+	// The webhook is opt-in because it requires serving certificates.
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&nodesv1alpha1.TaintRemover{}).SetupWebhookWithManager(mgr,
+			&nodesv1alpha1.TaintRemoverValidator{AllowedEffects: allowedEffects}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "TaintRemover")
+			return 1
+		}
 	}
 	//+kubebuilder:scaffold:builder
 
+	if err := addAdminHandlers(mgr, o.adminAddr, map[string]http.Handler{
+		"/status":    reconciler.StatusHandler(),
+		"/reconcile": reconciler.ReconcileHandler(),
+		"/loglevel":  o.logLevelHandler(),
+	}); err != nil {
+		setupLog.Error(err, "unable to set up admin handlers")
+		return 1
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
-		os.Exit(1)
+		return 1
 	}
 	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
-		os.Exit(1)
+		return 1
 	}
 
-	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
-		setupLog.Error(err, "problem running manager")
-		os.Exit(1)
+	ctx := ctrl.SetupSignalHandler()
+	if o.enableLeaderElection {
+		go logElection(ctx, mgr.Elected())
 	}
+	return startManager(ctx, mgr, o.maxRuntime)
 }
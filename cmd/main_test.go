@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// parseOptions parses the arguments into the controller options.
+func parseOptions(t *testing.T, args ...string) *options {
+	t.Helper()
+	o := &options{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	o.bindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return o
+}
+
+func TestManagerOptsCacheSyncPeriod(t *testing.T) {
+	t.Run("flag set", func(t *testing.T) {
+		o := parseOptions(t, "--cache-sync-period=5m")
+		opts := managerOpts(o)
+		if opts.Cache.SyncPeriod == nil {
+			t.Fatal("Expected SyncPeriod to be set, but it was nil")
+		}
+		if *opts.Cache.SyncPeriod != 5*time.Minute {
+			t.Errorf("SyncPeriod = %v, want %v", *opts.Cache.SyncPeriod, 5*time.Minute)
+		}
+	})
+
+	t.Run("flag unset", func(t *testing.T) {
+		o := parseOptions(t)
+		opts := managerOpts(o)
+		if opts.Cache.SyncPeriod != nil {
+			t.Errorf("Expected SyncPeriod to keep the default, got %v", *opts.Cache.SyncPeriod)
+		}
+	})
+}
+
+func TestManagerOpts(t *testing.T) {
+	o := parseOptions(t, "--metrics-bind-address=:9090", "--leader-elect")
+	opts := managerOpts(o)
+	if opts.Metrics.BindAddress != ":9090" {
+		t.Errorf("Metrics.BindAddress = %v, want %v", opts.Metrics.BindAddress, ":9090")
+	}
+	if !opts.LeaderElection {
+		t.Error("Expected LeaderElection to be enabled")
+	}
+	if opts.HealthProbeBindAddress != ":8081" {
+		t.Errorf("HealthProbeBindAddress = %v, want %v", opts.HealthProbeBindAddress, ":8081")
+	}
+}
+
+func TestApplyLogFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantJSON bool
+		wantErr  bool
+	}{
+		{name: "json", args: []string{"--log-format=json"}, wantJSON: true},
+		{name: "json in development mode", args: []string{"--log-format=json", "--zap-devel"}, wantJSON: true},
+		{name: "console", args: []string{"--log-format=console"}, wantJSON: false},
+		{name: "console in production mode", args: []string{"--log-format=console", "--zap-devel=false"}, wantJSON: false},
+		{name: "invalid", args: []string{"--log-format=xml"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := parseOptions(t, test.args...)
+			err := o.applyLogFormat()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("applyLogFormat() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+
+			var buf bytes.Buffer
+			zap.New(zap.UseFlagOptions(&o.zapOpts), zap.WriteTo(&buf)).Info("hello")
+			if got := json.Valid(bytes.TrimSpace(buf.Bytes())); got != test.wantJSON {
+				t.Errorf("JSON output = %v, want %v: %s", got, test.wantJSON, buf.String())
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{name: "empty", in: "", want: nil},
+		{name: "single", in: "a", want: []string{"a"}},
+		{name: "spaces and empty items", in: " a, ,b ,", want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitList(tt.in); !slices.Equal(got, tt.want) {
+				t.Errorf("splitList(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEffects(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []corev1.TaintEffect
+		wantErr bool
+	}{
+		{name: "empty", in: "", want: nil},
+		{name: "effects", in: "NoSchedule, PreferNoSchedule", want: []corev1.TaintEffect{
+			corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule,
+		}},
+		{name: "invalid effect", in: "NoSchedule,noexecute", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEffects(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEffects(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseEffects(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
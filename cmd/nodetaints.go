@@ -0,0 +1,176 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/norseto/taint-remover/internal/nodetaints"
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// newFlagSet returns a flag set for the subcommand that also accepts the
+// kubeconfig flag registered by controller-runtime.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	if f := flag.CommandLine.Lookup("kubeconfig"); f != nil {
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	return fs
+}
+
+// newClient creates a client for the cluster with the controller's scheme.
+func newClient() (client.Client, error) {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{Scheme: scheme})
+}
+
+// dumpNodeTaints writes the taints of all nodes to stdout as YAML.
+func dumpNodeTaints(args []string) int {
+	fs := newFlagSet("dump-node-taints")
+	_ = fs.Parse(args)
+
+	c, err := newClient()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unable to create client:", err)
+		return 1
+	}
+	dump, err := nodetaints.Collect(context.Background(), c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unable to list nodes:", err)
+		return 1
+	}
+	if err := dump.Write(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "unable to write node taints:", err)
+		return 1
+	}
+	return 0
+}
+
+// restoreNodeTaints adds the taints recorded by dump-node-taints back to the
+// nodes.
+func restoreNodeTaints(args []string) int {
+	fs := newFlagSet("restore-node-taints")
+	file := fs.String("file", "", "The YAML file written by dump-node-taints.")
+	_ = fs.Parse(args)
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "--file is required")
+		return 1
+	}
+
+	logger := zap.New()
+	ctx := log.IntoContext(context.Background(), logger)
+
+	f, err := os.Open(*file)
+	if err != nil {
+		logger.Error(err, "unable to open dump file")
+		return 1
+	}
+	defer f.Close()
+	dump, err := nodetaints.Read(f)
+	if err != nil {
+		logger.Error(err, "unable to read dump file")
+		return 1
+	}
+
+	c, err := newClient()
+	if err != nil {
+		logger.Error(err, "unable to create client")
+		return 1
+	}
+	restored, err := nodetaints.Restore(ctx, c, dump)
+	if err != nil {
+		logger.Error(err, "unable to restore node taints")
+		return 1
+	}
+	logger.Info("restored node taints", "nodes", restored)
+	return 0
+}
+
+// parseRemoveNodeArgs parses the node name and the taints of remove-node.
+// The node name may be given before or after the flags.
+func parseRemoveNodeArgs(fs *flag.FlagSet, args []string) (string, []corev1.Taint, error) {
+	taintsFlag := fs.String("taints", "", "Comma separated taints to remove, e.g. key:NoSchedule,key=value:NoExecute.")
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", nil, err
+	}
+	if name == "" {
+		name = fs.Arg(0)
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("node name is required")
+	}
+	if *taintsFlag == "" {
+		return "", nil, fmt.Errorf("--taints is required")
+	}
+	taints, _, err := tutil.ParseTaints(strings.Split(*taintsFlag, ","))
+	if err != nil {
+		return "", nil, err
+	}
+	return name, taints, nil
+}
+
+// removeNode removes the given taints from a single node.
+func removeNode(args []string) int {
+	name, taints, err := parseRemoveNodeArgs(newFlagSet("remove-node"), args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	logger := zap.New()
+	ctx := log.IntoContext(context.Background(), logger)
+
+	c, err := newClient()
+	if err != nil {
+		logger.Error(err, "unable to create client")
+		return 1
+	}
+	removed, err := nodetaints.Remove(ctx, c, name, taints)
+	if err != nil {
+		logger.Error(err, "unable to remove node taints")
+		return 1
+	}
+	logger.Info("removed node taints", "node", name, "removed", len(removed))
+	return 0
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseRemoveNodeArgs(t *testing.T) {
+	want := []corev1.Taint{
+		{Key: "foo", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "bar", Value: "baz", Effect: corev1.TaintEffectNoExecute},
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []corev1.Taint
+		wantErr bool
+	}{
+		{name: "node before flags", args: []string{"node1", "--taints", "foo:NoSchedule,bar=baz:NoExecute"}, want: want},
+		{name: "node after flags", args: []string{"--taints=foo:NoSchedule,bar=baz:NoExecute", "node1"}, want: want},
+		{name: "missing node", args: []string{"--taints=foo:NoSchedule"}, wantErr: true},
+		{name: "missing taints", args: []string{"node1"}, wantErr: true},
+		{name: "invalid taint", args: []string{"node1", "--taints=foo:Bogus"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("remove-node", flag.ContinueOnError)
+			name, taints, err := parseRemoveNodeArgs(fs, test.args)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseRemoveNodeArgs() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if name != "node1" {
+				t.Errorf("parseRemoveNodeArgs() name = %q, want %q", name, "node1")
+			}
+			if !reflect.DeepEqual(taints, test.want) {
+				t.Errorf("parseRemoveNodeArgs() taints = %v, want %v", taints, test.want)
+			}
+		})
+	}
+}
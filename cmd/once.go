@@ -0,0 +1,59 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/norseto/taint-remover/internal/controller"
+)
+
+const (
+	// exitPartialFailure is the exit code of a sweep that failed on some nodes.
+	exitPartialFailure = 2
+)
+
+// sweeper runs a single sweep over all nodes.
+type sweeper interface {
+	RunOnce(ctx context.Context) error
+}
+
+// runOnce runs a single sweep and returns the exit code reflecting its outcome.
+func runOnce(ctx context.Context, s sweeper) int {
+	err := s.RunOnce(ctx)
+	var partial *controller.PartialRemovalError
+	switch {
+	case err == nil:
+		setupLog.Info("sweep completed")
+		return 0
+	case errors.As(err, &partial):
+		setupLog.Error(err, "sweep partially failed", "succeeded", partial.Succeeded(), "failed", partial.Failed())
+		return exitPartialFailure
+	default:
+		setupLog.Error(err, "sweep failed")
+		return 1
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+	"github.com/norseto/taint-remover/internal/controller"
+)
+
+// fakeSweeper is a sweeper returning err and counting its sweeps.
+type fakeSweeper struct {
+	err    error
+	sweeps int
+}
+
+func (s *fakeSweeper) RunOnce(context.Context) error {
+	s.sweeps++
+	return s.err
+}
+
+func TestRunOnce(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", want: 0},
+		{name: "partial failure", err: &controller.PartialRemovalError{}, want: exitPartialFailure},
+		{name: "failure", err: errors.New("boom"), want: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &fakeSweeper{err: test.err}
+			if got := runOnce(context.TODO(), s); got != test.want {
+				t.Errorf("runOnce() = %d, want %d", got, test.want)
+			}
+			if s.sweeps != 1 {
+				t.Errorf("sweeps = %d, want 1", s.sweeps)
+			}
+		})
+	}
+}
+
+func TestRunOnceRemovesTaints(t *testing.T) {
+	taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+	}
+	tr := &nodesv1alpha1.TaintRemover{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+		Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node, tr).Build()
+
+	o := parseOptions(t, "--once")
+	if got := runOnce(context.TODO(), newReconciler(o, c, c, nil)); got != 0 {
+		t.Fatalf("runOnce() = %d, want 0", got)
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: node.Name}, node); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(node.Spec.Taints) != 0 {
+		t.Errorf("Taints = %v, want none", node.Spec.Taints)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// starter runs until the context is done.
+type starter interface {
+	Start(ctx context.Context) error
+}
+
+// startManager runs the manager and returns the exit code. The manager is
+// stopped cleanly after maxRuntime when it is positive.
+func startManager(ctx context.Context, mgr starter, maxRuntime time.Duration) int {
+	if maxRuntime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, maxRuntime)
+		defer cancel()
+	}
+	setupLog.Info("starting manager")
+	if err := mgr.Start(ctx); err != nil {
+		setupLog.Error(err, "problem running manager")
+		return 1
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		setupLog.Info("maximum runtime reached, manager stopped", "max-runtime", maxRuntime)
+	}
+	return 0
+}
+
+// logElection logs that the instance stands by until it is elected leader.
+// It reports whether the instance was elected before the context was done.
+func logElection(ctx context.Context, elected <-chan struct{}) bool {
+	setupLog.Info("standing by until elected leader")
+	select {
+	case <-elected:
+		setupLog.Info("elected leader, starting to remove taints")
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingStarter is a starter blocking until the context is done and
+// returning err.
+type blockingStarter struct {
+	err error
+}
+
+func (s *blockingStarter) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return s.err
+}
+
+func TestStartManager(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "stopped at max runtime", want: 0},
+		{name: "failure", err: errors.New("boom"), want: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			done := make(chan int)
+			go func() {
+				done <- startManager(context.Background(), &blockingStarter{err: test.err}, 10*time.Millisecond)
+			}()
+			select {
+			case got := <-done:
+				if got != test.want {
+					t.Errorf("startManager() = %d, want %d", got, test.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("manager did not stop at the max runtime")
+			}
+		})
+	}
+}
+
+// stubManager is a manager whose election is controlled by the test.
+type stubManager struct {
+	elected chan struct{}
+}
+
+func (m *stubManager) Elected() <-chan struct{} {
+	return m.elected
+}
+
+func TestLogElection(t *testing.T) {
+	mgr := &stubManager{elected: make(chan struct{})}
+	done := make(chan bool)
+	go func() {
+		done <- logElection(context.Background(), mgr.Elected())
+	}()
+	select {
+	case <-done:
+		t.Fatal("logElection() returned before the election")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(mgr.elected)
+	if !<-done {
+		t.Error("logElection() = false, want true after the election")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if logElection(ctx, make(chan struct{})) {
+		t.Error("logElection() = true, want false when cancelled")
+	}
+}
@@ -0,0 +1,140 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// specErrorReasons maps the taint spec errors to their reasons in the JSON
+// output, the more specific ones first.
+var specErrorReasons = []struct {
+	err    error
+	reason string
+}{
+	{tutil.ErrTaintKeyTooLong, "KeyTooLong"},
+	{tutil.ErrTaintKeyPrefix, "InvalidKeyPrefix"},
+	{tutil.ErrTaintKeyName, "InvalidKeyName"},
+	{tutil.ErrTaintValueTooLong, "ValueTooLong"},
+	{tutil.ErrTaintValue, "InvalidValue"},
+	{tutil.ErrInvalidTaintEffect, "InvalidEffect"},
+	{tutil.ErrDuplicateTaint, "DuplicateTaint"},
+	{tutil.ErrInvalidTaintSpec, "InvalidSpec"},
+}
+
+// specErrorReason returns the reason of the taint spec error.
+func specErrorReason(err error) string {
+	for _, r := range specErrorReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	return "Invalid"
+}
+
+// specErrorOutput is the JSON output of an invalid taint spec.
+type specErrorOutput struct {
+	Index  int    `json:"index"`
+	Spec   string `json:"spec"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+}
+
+// validationOutput is the JSON output of validate.
+type validationOutput struct {
+	Valid  bool              `json:"valid"`
+	Errors []specErrorOutput `json:"errors"`
+}
+
+// parseValidateArgs parses the taint specs and the output format of validate.
+func parseValidateArgs(fs *flag.FlagSet, args []string) ([]string, string, error) {
+	taintsFlag := fs.String("taints", "", "Comma separated taints to validate, e.g. key:NoSchedule,key=value:NoExecute.")
+	outputFlag := fs.String("output", "text", "The output format of the errors, text or json.")
+	if err := fs.Parse(args); err != nil {
+		return nil, "", err
+	}
+	if *taintsFlag == "" {
+		return nil, "", fmt.Errorf("--taints is required")
+	}
+	if *outputFlag != "text" && *outputFlag != "json" {
+		return nil, "", fmt.Errorf("invalid output %q, must be text or json", *outputFlag)
+	}
+	return strings.Split(*taintsFlag, ","), *outputFlag, nil
+}
+
+// renderValidation writes the errors of the invalid taint specs in the
+// output format. Nothing is written as text when all specs are valid.
+func renderValidation(w io.Writer, output string, errs []*tutil.TaintSpecError) error {
+	if output != "json" {
+		for _, e := range errs {
+			if _, err := fmt.Fprintln(w, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	out := validationOutput{Valid: len(errs) == 0, Errors: []specErrorOutput{}}
+	for _, e := range errs {
+		out.Errors = append(out.Errors, specErrorOutput{
+			Index:  e.Index,
+			Spec:   e.Spec,
+			Reason: specErrorReason(e.Err),
+			Error:  e.Err.Error(),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// validate checks the given taint specs, exiting with 1 when any is invalid.
+func validate(args []string) int {
+	specs, output, err := parseValidateArgs(flag.NewFlagSet("validate", flag.ExitOnError), args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	errs := tutil.ValidateTaints(specs)
+	w := io.Writer(os.Stderr)
+	if output == "json" {
+		w = os.Stdout
+	}
+	if err := renderValidation(w, output, errs); err != nil {
+		fmt.Fprintln(os.Stderr, "unable to write validation result:", err)
+		return 1
+	}
+	if len(errs) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"reflect"
+	"testing"
+
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+func TestParseValidateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "text", args: []string{"--taints", "foo:NoSchedule"}},
+		{name: "json", args: []string{"--taints", "foo:NoSchedule", "--output", "json"}},
+		{name: "missing taints", args: []string{"--output=json"}, wantErr: true},
+		{name: "invalid output", args: []string{"--taints=foo:NoSchedule", "--output=yaml"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+			_, _, err := parseValidateArgs(fs, test.args)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseValidateArgs() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderValidationJSON(t *testing.T) {
+	specs := []string{"foo:NoSchedule", "bar:Bogus", "bad@key:NoSchedule", "foo:NoSchedule", "baz"}
+	var buf bytes.Buffer
+	if err := renderValidation(&buf, "json", tutil.ValidateTaints(specs)); err != nil {
+		t.Fatalf("renderValidation() error = %v", err)
+	}
+
+	var out struct {
+		Valid  bool `json:"valid"`
+		Errors []struct {
+			Index  int    `json:"index"`
+			Spec   string `json:"spec"`
+			Reason string `json:"reason"`
+			Error  string `json:"error"`
+		} `json:"errors"`
+	}
+	decoder := json.NewDecoder(&buf)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&out); err != nil {
+		t.Fatalf("unable to decode output: %v", err)
+	}
+	if out.Valid {
+		t.Errorf("valid = true, want false")
+	}
+	var indexes, reasons []string
+	for _, e := range out.Errors {
+		indexes = append(indexes, specs[e.Index])
+		if e.Spec != specs[e.Index] {
+			t.Errorf("error %d spec = %q, want %q", e.Index, e.Spec, specs[e.Index])
+		}
+		if e.Error == "" {
+			t.Errorf("error %d has no message", e.Index)
+		}
+		reasons = append(reasons, e.Reason)
+	}
+	if want := specs[1:]; !reflect.DeepEqual(indexes, want) {
+		t.Errorf("invalid specs = %v, want %v", indexes, want)
+	}
+	if want := []string{"InvalidEffect", "InvalidKeyName", "DuplicateTaint", "InvalidSpec"}; !reflect.DeepEqual(reasons, want) {
+		t.Errorf("reasons = %v, want %v", reasons, want)
+	}
+}
+
+func TestRenderValidationJSONValid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderValidation(&buf, "json", tutil.ValidateTaints([]string{"foo:NoSchedule"})); err != nil {
+		t.Fatalf("renderValidation() error = %v", err)
+	}
+	if got, want := buf.String(), "{\n  \"valid\": true,\n  \"errors\": []\n}\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package celmatch compiles and evaluates the match expressions of the
+// TaintRemovers. They are written in a subset of the Common Expression
+// Language (CEL) and evaluated for each taint of a node, with the variables:
+//
+//	taint.key, taint.value, taint.effect  the node taint
+//	node.name, node.labels                the node and its labels
+//
+// The subset has string, int, bool and list literals, the operators !, &&,
+// ||, ==, !=, <, <=, >, >=, in and -, member selection and indexing, and the
+// functions size, startsWith, endsWith, contains and matches, e.g.
+//
+//	taint.key.startsWith("example.com/") && node.labels["pool"] == "gpu"
+package celmatch
+
+import (
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ErrInvalidExpression is returned for an expression that does not compile.
+var ErrInvalidExpression = errors.New("invalid match expression")
+
+// Program is a compiled match expression.
+type Program struct {
+	source string
+	root   expr
+}
+
+// Compile parses and checks the match expression, which must evaluate to a
+// bool. The regular expressions given literally to matches are compiled too.
+func Compile(source string) (*Program, error) {
+	p := &parser{lexer: newLexer(source)}
+	root, t, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExpression, err)
+	}
+	if t.kind != kindBool {
+		return nil, fmt.Errorf("%w: must evaluate to a bool, got %s", ErrInvalidExpression, t)
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (p *Program) String() string {
+	return p.source
+}
+
+// Match evaluates the expression for the taint of the node. It returns an
+// error when the evaluation fails, e.g. on a missing label.
+func (p *Program) Match(taint *corev1.Taint, node *corev1.Node) (bool, error) {
+	vars := map[string]any{
+		"taint": object{
+			"key":    taint.Key,
+			"value":  taint.Value,
+			"effect": string(taint.Effect),
+		},
+		"node": object{
+			"name":   node.Name,
+			"labels": stringMap(node.Labels),
+		},
+	}
+	v, err := p.root.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	return v.(bool), nil
+}
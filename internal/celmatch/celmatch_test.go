@@ -0,0 +1,95 @@
+package celmatch
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatch(t *testing.T) {
+	taint := &corev1.Taint{Key: "example.com/gpu", Value: "v12", Effect: corev1.TaintEffectNoSchedule}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "worker-1",
+		Labels: map[string]string{"pool": "gpu", "zone": "a"},
+	}}
+
+	tests := []struct {
+		name        string
+		expression  string
+		expected    bool
+		expectError bool
+	}{
+		{name: "key prefix", expression: `taint.key.startsWith("example.com/")`, expected: true},
+		{name: "key suffix", expression: `taint.key.endsWith("/cpu")`, expected: false},
+		{name: "key contains", expression: `taint.key.contains("gpu")`, expected: true},
+		{
+			name:       "node label and effect",
+			expression: `node.labels["pool"] == "gpu" && taint.effect == "NoSchedule"`,
+			expected:   true,
+		},
+		{name: "other effect", expression: `taint.effect != "NoSchedule"`, expected: false},
+		{name: "key in list", expression: `taint.key in ["example.com/cpu", "example.com/gpu"]`, expected: true},
+		{name: "label present", expression: `"pool" in node.labels`, expected: true},
+		{name: "label absent", expression: `!("team" in node.labels)`, expected: true},
+		{name: "value pattern", expression: `taint.value.matches("^v[0-9]+$")`, expected: true},
+		{name: "value size", expression: `size(taint.value) > 2`, expected: true},
+		{name: "label count", expression: `size(node.labels) <= 1`, expected: false},
+		{name: "node name", expression: `node.name == "worker-1" || false`, expected: true},
+		{name: "string order", expression: `node.labels["zone"] < "b"`, expected: true},
+		{name: "negative int", expression: `-1 < size(taint.key)`, expected: true},
+		{name: "missing label", expression: `node.labels["team"] == "ml"`, expectError: true},
+		{
+			name:       "missing label decided by the other operand",
+			expression: `node.labels["team"] == "ml" || taint.key == "example.com/gpu"`,
+			expected:   true,
+		},
+		{name: "index out of range", expression: `["a"][1] == "a"`, expectError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := Compile(test.expression)
+			if err != nil {
+				t.Fatalf("Compile(%s) returned unexpected error: %v", test.expression, err)
+			}
+			matched, err := p.Match(taint, node)
+			if test.expectError && err == nil {
+				t.Errorf("Match(%s) expected error, got none", test.expression)
+			} else if !test.expectError && err != nil {
+				t.Errorf("Match(%s) returned unexpected error: %v", test.expression, err)
+			} else if matched != test.expected {
+				t.Errorf("Match(%s) = %v, want %v", test.expression, matched, test.expected)
+			}
+		})
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{name: "empty", expression: ""},
+		{name: "syntax error", expression: `taint.key ==`},
+		{name: "unterminated string", expression: `taint.key == "a`},
+		{name: "trailing token", expression: `true false`},
+		{name: "unknown variable", expression: `pod.name == "a"`},
+		{name: "unknown field", expression: `taint.operator == "Exists"`},
+		{name: "unknown function", expression: `taint.key.lowerAscii() == "a"`},
+		{name: "not a bool", expression: `taint.key`},
+		{name: "type mismatch", expression: `taint.key == 1`},
+		{name: "not a bool operand", expression: `taint.key && true`},
+		{name: "bad regular expression", expression: `taint.key.matches("(")`},
+		{name: "wrong argument count", expression: `taint.key.startsWith()`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Compile(test.expression); !errors.Is(err, ErrInvalidExpression) {
+				t.Errorf("Compile(%s) returned %v, want %v", test.expression, err, ErrInvalidExpression)
+			}
+		})
+	}
+}
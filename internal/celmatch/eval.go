@@ -0,0 +1,288 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package celmatch
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// expr is a checked expression. It evaluates to a bool, int64, string,
+// []any, stringMap or object.
+type expr interface {
+	eval(vars map[string]any) (any, error)
+}
+
+type literalExpr struct{ v any }
+
+func (e *literalExpr) eval(map[string]any) (any, error) {
+	return e.v, nil
+}
+
+type varExpr struct{ name string }
+
+func (e *varExpr) eval(vars map[string]any) (any, error) {
+	return vars[e.name], nil
+}
+
+type listExpr struct{ elems []expr }
+
+func (e *listExpr) eval(vars map[string]any) (any, error) {
+	list := make([]any, 0, len(e.elems))
+	for _, elem := range e.elems {
+		v, err := elem.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+type selectExpr struct {
+	operand expr
+	field   string
+}
+
+func (e *selectExpr) eval(vars map[string]any) (any, error) {
+	v, err := e.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return v.(object)[e.field], nil
+}
+
+type indexExpr struct{ operand, index expr }
+
+func (e *indexExpr) eval(vars map[string]any) (any, error) {
+	v, err := e.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	i, err := e.index.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case stringMap:
+		s, ok := v[i.(string)]
+		if !ok {
+			return nil, fmt.Errorf("no such key: %q", i)
+		}
+		return s, nil
+	default:
+		list, n := v.([]any), i.(int64)
+		if n < 0 || n >= int64(len(list)) {
+			return nil, fmt.Errorf("index out of range: %d", n)
+		}
+		return list[n], nil
+	}
+}
+
+// andExpr and orExpr evaluate both operands like CEL, so that an error on
+// one side is ignored when the other side decides the result.
+type andExpr struct{ left, right expr }
+
+func (e *andExpr) eval(vars map[string]any) (any, error) {
+	return logical(vars, e.left, e.right, false)
+}
+
+type orExpr struct{ left, right expr }
+
+func (e *orExpr) eval(vars map[string]any) (any, error) {
+	return logical(vars, e.left, e.right, true)
+}
+
+// logical returns decisive when either operand is decisive, and the other
+// bool otherwise.
+func logical(vars map[string]any, left, right expr, decisive bool) (any, error) {
+	l, lerr := left.eval(vars)
+	if lerr == nil && l.(bool) == decisive {
+		return decisive, nil
+	}
+	r, rerr := right.eval(vars)
+	if rerr == nil && r.(bool) == decisive {
+		return decisive, nil
+	}
+	if lerr != nil {
+		return nil, lerr
+	}
+	if rerr != nil {
+		return nil, rerr
+	}
+	return !decisive, nil
+}
+
+type notExpr struct{ operand expr }
+
+func (e *notExpr) eval(vars map[string]any) (any, error) {
+	v, err := e.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return !v.(bool), nil
+}
+
+type negExpr struct{ operand expr }
+
+func (e *negExpr) eval(vars map[string]any) (any, error) {
+	v, err := e.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return -v.(int64), nil
+}
+
+type equalExpr struct {
+	left, right expr
+	negate      bool
+}
+
+func (e *equalExpr) eval(vars map[string]any) (any, error) {
+	l, r, err := evalBoth(vars, e.left, e.right)
+	if err != nil {
+		return nil, err
+	}
+	return reflect.DeepEqual(l, r) != e.negate, nil
+}
+
+type orderExpr struct {
+	left, right expr
+	op          string
+}
+
+func (e *orderExpr) eval(vars map[string]any) (any, error) {
+	l, r, err := evalBoth(vars, e.left, e.right)
+	if err != nil {
+		return nil, err
+	}
+	var c int
+	if ls, ok := l.(string); ok {
+		c = strings.Compare(ls, r.(string))
+	} else {
+		c = cmp.Compare(l.(int64), r.(int64))
+	}
+	switch e.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+type inExpr struct{ elem, container expr }
+
+func (e *inExpr) eval(vars map[string]any) (any, error) {
+	v, c, err := evalBoth(vars, e.elem, e.container)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := c.(stringMap); ok {
+		_, found := m[v.(string)]
+		return found, nil
+	}
+	for _, elem := range c.([]any) {
+		if reflect.DeepEqual(elem, v) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type sizeExpr struct{ operand expr }
+
+func (e *sizeExpr) eval(vars map[string]any) (any, error) {
+	v, err := e.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case string:
+		return int64(utf8.RuneCountInString(v)), nil
+	case stringMap:
+		return int64(len(v)), nil
+	default:
+		return int64(len(v.([]any))), nil
+	}
+}
+
+type stringCallExpr struct {
+	fn     func(s, arg string) bool
+	s, arg expr
+}
+
+func (e *stringCallExpr) eval(vars map[string]any) (any, error) {
+	s, arg, err := evalBoth(vars, e.s, e.arg)
+	if err != nil {
+		return nil, err
+	}
+	return e.fn(s.(string), arg.(string)), nil
+}
+
+func hasPrefix(s, prefix string) bool { return strings.HasPrefix(s, prefix) }
+func hasSuffix(s, suffix string) bool { return strings.HasSuffix(s, suffix) }
+func contains(s, substr string) bool  { return strings.Contains(s, substr) }
+
+// matchesExpr matches a string against a regular expression, which is
+// compiled once when it is given literally.
+type matchesExpr struct {
+	s, pattern expr
+	re         *regexp.Regexp
+}
+
+func (e *matchesExpr) eval(vars map[string]any) (any, error) {
+	s, pattern, err := evalBoth(vars, e.s, e.pattern)
+	if err != nil {
+		return nil, err
+	}
+	re := e.re
+	if re == nil {
+		if re, err = regexp.Compile(pattern.(string)); err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+	return re.MatchString(s.(string)), nil
+}
+
+// evalBoth evaluates both operands.
+func evalBoth(vars map[string]any, left, right expr) (any, any, error) {
+	l, err := left.eval(vars)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := right.eval(vars)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, r, nil
+}
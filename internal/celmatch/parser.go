@@ -0,0 +1,442 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package celmatch
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// parser parses an expression by recursive descent, checking the types of
+// its operands as it goes.
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+// parse parses the whole expression and returns it with its type.
+func (p *parser) parse() (expr, *typ, error) {
+	if err := p.advance(); err != nil {
+		return nil, nil, err
+	}
+	e, t, err := p.or()
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, nil, p.unexpected()
+	}
+	return e, t, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// accept consumes the token when it is the punctuation.
+func (p *parser) accept(punct string) (bool, error) {
+	if p.tok.kind != tokenPunct || p.tok.text != punct {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+// expect consumes the punctuation or fails.
+func (p *parser) expect(punct string) error {
+	ok, err := p.accept(punct)
+	if err == nil && !ok {
+		err = fmt.Errorf("column %d: expected %q, got %s", p.tok.pos+1, punct, p.tok)
+	}
+	return err
+}
+
+func (p *parser) unexpected() error {
+	return fmt.Errorf("column %d: unexpected %s", p.tok.pos+1, p.tok)
+}
+
+// errorf returns an error at the position of the token.
+func errorf(tok token, format string, args ...any) error {
+	return fmt.Errorf("column %d: %s", tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) or() (expr, *typ, error) {
+	return p.logical("||", p.and, func(l, r expr) expr { return &orExpr{l, r} })
+}
+
+func (p *parser) and() (expr, *typ, error) {
+	return p.logical("&&", p.relation, func(l, r expr) expr { return &andExpr{l, r} })
+}
+
+// logical parses the operands of a left-associative logical operator.
+func (p *parser) logical(op string, operand func() (expr, *typ, error),
+	combine func(l, r expr) expr) (expr, *typ, error) {
+	tok := p.tok
+	left, lt, err := operand()
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		ok, err := p.accept(op)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			return left, lt, nil
+		}
+		if lt.kind != kindBool {
+			return nil, nil, errorf(tok, "%s operand must be a bool, got %s", op, lt)
+		}
+		rightTok := p.tok
+		right, rt, err := operand()
+		if err != nil {
+			return nil, nil, err
+		}
+		if rt.kind != kindBool {
+			return nil, nil, errorf(rightTok, "%s operand must be a bool, got %s", op, rt)
+		}
+		left, lt = combine(left, right), boolType
+	}
+}
+
+// relations are the relational operators.
+var relations = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+func (p *parser) relation() (expr, *typ, error) {
+	left, lt, err := p.unary()
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		op := p.tok
+		isIn := op.kind == tokenIdent && op.text == "in"
+		if !isIn && (op.kind != tokenPunct || !relations[op.text]) {
+			return left, lt, nil
+		}
+		if err := p.advance(); err != nil {
+			return nil, nil, err
+		}
+		right, rt, err := p.unary()
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case isIn:
+			if err := checkIn(op, lt, rt); err != nil {
+				return nil, nil, err
+			}
+			left = &inExpr{left, right}
+		case op.text == "==" || op.text == "!=":
+			if !assignable(lt, rt) {
+				return nil, nil, errorf(op, "cannot compare %s and %s", lt, rt)
+			}
+			left = &equalExpr{left, right, op.text == "!="}
+		default:
+			if !assignable(lt, rt) || (lt.kind != kindInt && lt.kind != kindString) {
+				return nil, nil, errorf(op, "cannot order %s and %s", lt, rt)
+			}
+			left = &orderExpr{left, right, op.text}
+		}
+		lt = boolType
+	}
+}
+
+// checkIn checks the operands of in: an element of a list or a key of a map.
+func checkIn(op token, lt, rt *typ) error {
+	switch {
+	case rt.kind == kindList && assignable(lt, rt.elem):
+		return nil
+	case rt.kind == kindMap && lt.kind == kindString:
+		return nil
+	default:
+		return errorf(op, "cannot look for %s in %s", lt, rt)
+	}
+}
+
+func (p *parser) unary() (expr, *typ, error) {
+	op := p.tok
+	for _, punct := range []string{"!", "-"} {
+		ok, err := p.accept(punct)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			continue
+		}
+		operand, t, err := p.unary()
+		if err != nil {
+			return nil, nil, err
+		}
+		if punct == "!" {
+			if t.kind != kindBool {
+				return nil, nil, errorf(op, "! operand must be a bool, got %s", t)
+			}
+			return &notExpr{operand}, boolType, nil
+		}
+		if t.kind != kindInt {
+			return nil, nil, errorf(op, "- operand must be an int, got %s", t)
+		}
+		return &negExpr{operand}, intType, nil
+	}
+	return p.member()
+}
+
+func (p *parser) member() (expr, *typ, error) {
+	e, t, err := p.primary()
+	if err != nil {
+		return nil, nil, err
+	}
+	for {
+		op := p.tok
+		if ok, err := p.accept("."); err != nil {
+			return nil, nil, err
+		} else if ok {
+			name := p.tok
+			if name.kind != tokenIdent {
+				return nil, nil, p.unexpected()
+			}
+			if err := p.advance(); err != nil {
+				return nil, nil, err
+			}
+			if p.tok.kind == tokenPunct && p.tok.text == "(" {
+				e, t, err = p.call(name, e, t)
+			} else {
+				e, t, err = selectField(name, e, t)
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if ok, err := p.accept("["); err != nil {
+			return nil, nil, err
+		} else if ok {
+			index, it, err := p.or()
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, nil, err
+			}
+			switch {
+			case t.kind == kindList && t.elem != nil && it.kind == kindInt:
+			case t.kind == kindMap && it.kind == kindString:
+			default:
+				return nil, nil, errorf(op, "cannot index %s with %s", t, it)
+			}
+			e, t = &indexExpr{e, index}, t.elem
+			continue
+		}
+		return e, t, nil
+	}
+}
+
+// selectField checks the selection of a field of an object or a key of a map.
+func selectField(name token, e expr, t *typ) (expr, *typ, error) {
+	switch t.kind {
+	case kindObject:
+		ft, ok := t.fields[name.text]
+		if !ok {
+			return nil, nil, errorf(name, "%s has no field %q", t, name.text)
+		}
+		return &selectExpr{e, name.text}, ft, nil
+	case kindMap:
+		return &indexExpr{e, &literalExpr{name.text}}, t.elem, nil
+	default:
+		return nil, nil, errorf(name, "cannot select %q of %s", name.text, t)
+	}
+}
+
+func (p *parser) primary() (expr, *typ, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenString:
+		return &literalExpr{tok.text}, stringType, p.advance()
+	case tokenInt:
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return nil, nil, errorf(tok, "invalid int %s", tok.text)
+		}
+		return &literalExpr{n}, intType, p.advance()
+	case tokenIdent:
+		if err := p.advance(); err != nil {
+			return nil, nil, err
+		}
+		switch tok.text {
+		case "true", "false":
+			return &literalExpr{tok.text == "true"}, boolType, nil
+		}
+		if p.tok.kind == tokenPunct && p.tok.text == "(" {
+			return p.call(tok, nil, nil)
+		}
+		t, ok := variables[tok.text]
+		if !ok {
+			return nil, nil, errorf(tok, "undeclared reference to %q", tok.text)
+		}
+		return &varExpr{tok.text}, t, nil
+	case tokenPunct:
+		switch tok.text {
+		case "(":
+			if err := p.advance(); err != nil {
+				return nil, nil, err
+			}
+			e, t, err := p.or()
+			if err != nil {
+				return nil, nil, err
+			}
+			return e, t, p.expect(")")
+		case "[":
+			return p.list()
+		}
+	}
+	return nil, nil, p.unexpected()
+}
+
+// list parses a list literal, whose elements must have the same type.
+func (p *parser) list() (expr, *typ, error) {
+	if err := p.advance(); err != nil {
+		return nil, nil, err
+	}
+	l := &listExpr{}
+	t := &typ{kind: kindList, name: "list"}
+	for {
+		if ok, err := p.accept("]"); err != nil || ok {
+			return l, t, err
+		}
+		if len(l.elems) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, nil, err
+			}
+		}
+		tok := p.tok
+		e, et, err := p.or()
+		if err != nil {
+			return nil, nil, err
+		}
+		if t.elem != nil && !assignable(t.elem, et) {
+			return nil, nil, errorf(tok, "list elements must have the same type, got %s and %s", t.elem, et)
+		}
+		if t.elem == nil {
+			t.elem = et
+		}
+		l.elems = append(l.elems, e)
+	}
+}
+
+// args parses the arguments of a call.
+func (p *parser) args() ([]expr, []*typ, []token, error) {
+	if err := p.expect("("); err != nil {
+		return nil, nil, nil, err
+	}
+	var args []expr
+	var types []*typ
+	var toks []token
+	for {
+		if ok, err := p.accept(")"); err != nil || ok {
+			return args, types, toks, err
+		}
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		toks = append(toks, p.tok)
+		e, t, err := p.or()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		args = append(args, e)
+		types = append(types, t)
+	}
+}
+
+// stringFunctions are the functions of a string receiver taking a string.
+var stringFunctions = map[string]func(s, arg string) bool{
+	"startsWith": hasPrefix,
+	"endsWith":   hasSuffix,
+	"contains":   contains,
+}
+
+// call checks a call of the function, on the receiver when it is not nil.
+func (p *parser) call(name token, recv expr, rt *typ) (expr, *typ, error) {
+	args, types, toks, err := p.args()
+	if err != nil {
+		return nil, nil, err
+	}
+	if recv != nil {
+		args = append([]expr{recv}, args...)
+		types = append([]*typ{rt}, types...)
+		toks = append([]token{name}, toks...)
+	}
+	switch name.text {
+	case "size":
+		if len(args) != 1 {
+			return nil, nil, errorf(name, "size takes 1 argument, got %d", len(args))
+		}
+		if k := types[0].kind; k != kindString && k != kindList && k != kindMap {
+			return nil, nil, errorf(toks[0], "cannot take the size of %s", types[0])
+		}
+		return &sizeExpr{args[0]}, intType, nil
+	case "matches":
+		if err := checkStringCall(name, recv, types); err != nil {
+			return nil, nil, err
+		}
+		m := &matchesExpr{s: args[0], pattern: args[1]}
+		if lit, ok := args[1].(*literalExpr); ok {
+			re, err := regexp.Compile(lit.v.(string))
+			if err != nil {
+				return nil, nil, errorf(toks[1], "invalid regular expression: %v", err)
+			}
+			m.re = re
+		}
+		return m, boolType, nil
+	}
+	if fn, ok := stringFunctions[name.text]; ok {
+		if err := checkStringCall(name, recv, types); err != nil {
+			return nil, nil, err
+		}
+		return &stringCallExpr{fn: fn, s: args[0], arg: args[1]}, boolType, nil
+	}
+	return nil, nil, errorf(name, "undeclared reference to function %q", name.text)
+}
+
+// checkStringCall checks a call of a string receiver with a string argument.
+func checkStringCall(name token, recv expr, types []*typ) error {
+	if recv == nil {
+		return errorf(name, "%s must be called on a string", name.text)
+	}
+	if len(types) != 2 {
+		return errorf(name, "%s takes 1 argument, got %d", name.text, len(types)-1)
+	}
+	if types[0].kind != kindString || types[1].kind != kindString {
+		return errorf(name, "%s takes a string on a string, got %s on %s", name.text, types[1], types[0])
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package celmatch
+
+// kind is the kind of a value.
+type kind int
+
+const (
+	kindBool kind = iota
+	kindInt
+	kindString
+	kindList
+	kindMap
+	kindObject
+)
+
+// typ is the static type of an expression. Lists and maps have the type of
+// their elements, which is nil for an empty list literal, and objects the
+// types of their fields.
+type typ struct {
+	kind   kind
+	name   string
+	elem   *typ
+	fields map[string]*typ
+}
+
+var (
+	boolType   = &typ{kind: kindBool, name: "bool"}
+	intType    = &typ{kind: kindInt, name: "int"}
+	stringType = &typ{kind: kindString, name: "string"}
+	labelsType = &typ{kind: kindMap, name: "map", elem: stringType}
+	taintType  = &typ{kind: kindObject, name: "taint", fields: map[string]*typ{
+		"key": stringType, "value": stringType, "effect": stringType,
+	}}
+	nodeType = &typ{kind: kindObject, name: "node", fields: map[string]*typ{
+		"name": stringType, "labels": labelsType,
+	}}
+)
+
+// variables are the types of the variables of an expression.
+var variables = map[string]*typ{
+	"taint": taintType,
+	"node":  nodeType,
+}
+
+func (t *typ) String() string {
+	if t.kind == kindList {
+		if t.elem == nil {
+			return "list"
+		}
+		return "list(" + t.elem.String() + ")"
+	}
+	if t.kind == kindMap {
+		return "map(string, " + t.elem.String() + ")"
+	}
+	return t.name
+}
+
+// assignable reports whether the values of both types may be compared. A
+// list of unknown elements compares with any list.
+func assignable(a, b *typ) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	if a.kind != b.kind {
+		return false
+	}
+	switch a.kind {
+	case kindList, kindMap:
+		return assignable(a.elem, b.elem)
+	case kindObject:
+		return a.name == b.name
+	default:
+		return true
+	}
+}
+
+// object is the value of a taint or a node.
+type object map[string]any
+
+// stringMap is the value of a map of strings, such as the node labels.
+type stringMap map[string]string
@@ -0,0 +1,83 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	taintremover "github.com/norseto/taint-remover"
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// actorController names the controller in the last actor annotation.
+const actorController = "taint-remover"
+
+// lastActor identifies the controller instance that last swept with a
+// TaintRemover.
+type lastActor struct {
+	Controller string `json:"controller"`
+	Version    string `json:"version"`
+	Pod        string `json:"pod,omitempty"`
+}
+
+// lastActorValue returns the last actor annotation value of this instance.
+// It has no time so that a sweep does not change an up to date remover.
+func (r *TaintRemoverReconciler) lastActorValue() string {
+	data, _ := json.Marshal(lastActor{Controller: actorController, Version: taintremover.Version, Pod: r.PodName})
+	return string(data)
+}
+
+// stampLastActor stamps the removers with the last actor annotation of this
+// instance. It is best effort, so failures are only logged.
+func (r *TaintRemoverReconciler) stampLastActor(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	removers := &nodesv1alpha1.TaintRemoverList{}
+	if err := r.List(ctx, removers, r.removerListOptions()...); err != nil {
+		if !isMissingCRD(err) {
+			checkForbidden(ctx, err, "list", "taintremovers")
+			logger.Error(err, "Failed to list TaintRemovers for the last actor")
+		}
+		return
+	}
+
+	value := r.lastActorValue()
+	for i := range removers.Items {
+		remover := &removers.Items[i]
+		if remover.Annotations[nodesv1alpha1.LastActorAnnotation] == value {
+			continue
+		}
+		patch := client.MergeFrom(remover.DeepCopy())
+		metav1.SetMetaDataAnnotation(&remover.ObjectMeta, nodesv1alpha1.LastActorAnnotation, value)
+		if err := r.Patch(ctx, remover, patch); err != nil {
+			checkForbidden(ctx, err, "patch", "taintremovers")
+			logger.Error(err, "Failed to stamp the last actor", "remover", remover.Name)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	taintremover "github.com/norseto/taint-remover"
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("last actor", func() {
+	var (
+		ctx     context.Context
+		taint   corev1.Taint
+		node    *corev1.Node
+		remover *nodesv1alpha1.TaintRemover
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		taint = corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		remover = &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}},
+		}
+	})
+
+	actorOf := func(c client.Client) *lastActor {
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(remover), remover)).To(Succeed())
+		value, ok := remover.Annotations[nodesv1alpha1.LastActorAnnotation]
+		if !ok {
+			return nil
+		}
+		actor := &lastActor{}
+		Expect(json.Unmarshal([]byte(value), actor)).To(Succeed())
+		return actor
+	}
+
+	It("should stamp the remover after a successful sweep", func() {
+		c := newFakeClient(node, remover)
+		reconciler := &TaintRemoverReconciler{Client: c, PodName: "taint-remover-0"}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actorOf(c)).To(Equal(&lastActor{
+			Controller: actorController, Version: taintremover.Version, Pod: "taint-remover-0",
+		}))
+	})
+
+	It("should stamp the remover when no node is tainted", func() {
+		node.Spec.Taints = nil
+		c := newFakeClient(node, remover)
+		reconciler := &TaintRemoverReconciler{Client: c, PodName: "taint-remover-0"}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actorOf(c)).NotTo(BeNil())
+	})
+
+	It("should not patch an up to date remover again", func() {
+		c := &countingRemoverPatchClient{Client: newFakeClient(node, remover)}
+		reconciler := &TaintRemoverReconciler{Client: c, PodName: "taint-remover-0"}
+		for i := 0; i < 2; i++ {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(c.patches).To(Equal(1))
+	})
+
+	It("should replace the actor of another instance", func() {
+		remover.Annotations = map[string]string{nodesv1alpha1.LastActorAnnotation: `{"pod":"taint-remover-1"}`}
+		c := newFakeClient(node, remover)
+		reconciler := &TaintRemoverReconciler{Client: c, PodName: "taint-remover-0"}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actorOf(c).Pod).To(Equal("taint-remover-0"))
+	})
+
+	It("should not stamp the remover while standing by", func() {
+		c := newFakeClient(node, remover)
+		reconciler := &TaintRemoverReconciler{Client: c, Elected: make(chan struct{})}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actorOf(c)).To(BeNil())
+	})
+})
+
+// countingRemoverPatchClient is a client that counts TaintRemover patches.
+type countingRemoverPatchClient struct {
+	client.Client
+	patches int
+}
+
+func (c *countingRemoverPatchClient) Patch(ctx context.Context, obj client.Object, patch client.Patch,
+	opts ...client.PatchOption) error {
+	if _, ok := obj.(*nodesv1alpha1.TaintRemover); ok {
+		c.patches++
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("AllowedTaintKeys", func() {
+	var (
+		allowed corev1.Taint
+		other   corev1.Taint
+		node    *corev1.Node
+		targets []*removeTarget
+	)
+
+	BeforeEach(func() {
+		allowed = corev1.Taint{Key: "allowed", Effect: corev1.TaintEffectNoSchedule}
+		other = corev1.Taint{Key: "other", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{allowed, other}},
+		}
+		targets = []*removeTarget{{Taint: allowed}, {Taint: other}}
+	})
+
+	It("should keep the taints whose key is not allowed", func() {
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(node), AllowedTaintKeys: []string{"allowed"}}
+		result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.TaintsRemoved).To(Equal(1))
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{other}))
+	})
+
+	It("should allow any key when empty", func() {
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(node)}
+		result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.TaintsRemoved).To(Equal(2))
+	})
+})
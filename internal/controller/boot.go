@@ -0,0 +1,62 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// bootIDs tracks the last seen boot ID of each node, so that a node that
+// rebooted is processed again even when its taints look the same.
+type bootIDs struct {
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+// observe records the boot ID of the node and reports whether it changed
+// since the node was last seen. Nodes without a boot ID are not tracked.
+func (b *bootIDs) observe(node *corev1.Node) bool {
+	id := node.Status.NodeInfo.BootID
+	if id == "" {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ids == nil {
+		b.ids = make(map[string]string)
+	}
+	last, seen := b.ids[node.Name]
+	b.ids[node.Name] = id
+	return seen && last != id
+}
+
+// forget drops the boot ID of the deleted node.
+func (b *bootIDs) forget(node string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.ids, node)
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Boot IDs", func() {
+	It("should report a changed boot ID of a seen node", func() {
+		b := &bootIDs{}
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+		Expect(b.observe(node)).To(BeFalse())
+		node.Status.NodeInfo.BootID = "boot-1"
+		Expect(b.observe(node)).To(BeFalse())
+		Expect(b.observe(node)).To(BeFalse())
+		node.Status.NodeInfo.BootID = "boot-2"
+		Expect(b.observe(node)).To(BeTrue())
+		Expect(b.observe(node)).To(BeFalse())
+	})
+
+	It("should forget deleted nodes", func() {
+		b := &bootIDs{}
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+		node.Status.NodeInfo.BootID = "boot-1"
+		b.observe(node)
+		b.forget(node.Name)
+		node.Status.NodeInfo.BootID = "boot-2"
+		Expect(b.observe(node)).To(BeFalse())
+	})
+
+	It("should remove the taints again after a reboot", func() {
+		ctx := context.TODO()
+		now := time.Now()
+		taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		c := &countingClient{Client: newFakeClient(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{BootID: "boot-1"}},
+		})}
+		reconciler := &TaintRemoverReconciler{Client: c, now: func() time.Time { return now }}
+		node := &corev1.Node{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "test-node"}, node)).To(Succeed())
+		targets := []*removeTarget{{Taint: taint}}
+
+		result, err := reconciler.removeTaints(ctx, []*corev1.Node{node.DeepCopy()}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+
+		result, err = reconciler.removeTaints(ctx, []*corev1.Node{node.DeepCopy()}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(BeZero())
+
+		node.Status.NodeInfo.BootID = "boot-2"
+		result, err = reconciler.removeTaints(ctx, []*corev1.Node{node.DeepCopy()}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+		Expect(c.patches.Load()).To(Equal(int32(2)))
+	})
+})
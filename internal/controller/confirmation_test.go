@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("RequireConfirmation", func() {
+	var (
+		ctx       context.Context
+		blessed   corev1.Taint
+		unblessed corev1.Taint
+		removers  []client.Object
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		blessed = corev1.Taint{Key: "example.com/blessed", Effect: corev1.TaintEffectNoSchedule}
+		unblessed = corev1.Taint{Key: "example.com/unblessed", Effect: corev1.TaintEffectNoSchedule}
+		removers = []client.Object{
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "blessed",
+					Annotations: map[string]string{nodesv1alpha1.ConfirmedAnnotation: "true"},
+				},
+				Spec: nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{blessed}},
+			},
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "unblessed",
+					Annotations: map[string]string{nodesv1alpha1.ConfirmedAnnotation: "false"},
+				},
+				Spec: nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{unblessed}},
+			},
+		}
+	})
+
+	targetTaints := func(src targetSource, recorder record.EventRecorder) []corev1.Taint {
+		targets, err := getAllRemoveTaints(ctx, newFakeClient(removers...), recorder, src)
+		Expect(err).NotTo(HaveOccurred())
+		var taints []corev1.Taint
+		for _, t := range targets {
+			taints = append(taints, t.Taint)
+		}
+		return taints
+	}
+
+	It("should use every remover when confirmation is not required", func() {
+		Expect(targetTaints(targetSource{}, nil)).To(Equal([]corev1.Taint{blessed, unblessed}))
+	})
+
+	It("should skip the unconfirmed removers with an event", func() {
+		recorder := record.NewFakeRecorder(10)
+		Expect(targetTaints(targetSource{requireConfirmation: true}, recorder)).To(Equal([]corev1.Taint{blessed}))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Unconfirmed")))
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should keep the taints of the unconfirmed removers on the nodes", func() {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{blessed, unblessed}},
+		}
+		c := newFakeClient(append(removers, node)...)
+		reconciler := &TaintRemoverReconciler{Client: c, RequireConfirmation: true}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+
+		found := &corev1.Node{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, found)).To(Succeed())
+		Expect(found.Spec.Taints).To(Equal([]corev1.Taint{unblessed}))
+	})
+})
@@ -0,0 +1,152 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxContestBackoffShift caps the doubling of the backoff of a contested
+// taint.
+const maxContestBackoffShift = 6
+
+// contestEntry records the removals of a taint from a node that were each
+// followed by the taint being added back.
+type contestEntry struct {
+	removed time.Time
+	count   int
+}
+
+// contestedTaints tracks the taints that another controller adds back
+// right after their removal, so that the reconciler stops fighting over them.
+type contestedTaints struct {
+	mu      sync.Mutex
+	entries map[string]contestEntry
+}
+
+// contestBackoff returns how long a taint removed count times in a row is kept
+// after its last removal. It doubles with each removal past the threshold.
+func contestBackoff(count, threshold int, ttl time.Duration) time.Duration {
+	if threshold <= 0 || count < threshold {
+		return 0
+	}
+	return ttl << min(count-threshold, maxContestBackoffShift)
+}
+
+// recordRemoval records the removal of the taint from the node at now. The
+// removal continues the contest when it comes within the TTL, after the
+// backoff, of the previous one. It returns the number of removals in a row.
+func (c *contestedTaints) recordRemoval(node string, taint *corev1.Taint, now time.Time,
+	ttl time.Duration, threshold int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]contestEntry{}
+	}
+	key := nodeTaintKey(node, taint)
+	entry, ok := c.entries[key]
+	if ok && now.Sub(entry.removed) <= ttl+contestBackoff(entry.count, threshold, ttl) {
+		entry.count++
+	} else {
+		entry.count = 1
+	}
+	entry.removed = now
+	c.entries[key] = entry
+	return entry.count
+}
+
+// backingOff reports whether the taint on the node is contested and still
+// within its backoff at now.
+func (c *contestedTaints) backingOff(node string, taint *corev1.Taint, now time.Time,
+	ttl time.Duration, threshold int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[nodeTaintKey(node, taint)]
+	return ok && now.Before(entry.removed.Add(contestBackoff(entry.count, threshold, ttl)))
+}
+
+// prune forgets the contests that ended before now.
+func (c *contestedTaints) prune(now time.Time, ttl time.Duration, threshold int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if now.Sub(entry.removed) > ttl+contestBackoff(entry.count, threshold, ttl) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// next returns the time until the earliest backoff ends, or zero if no
+// taint is backing off.
+func (c *contestedTaints) next(now time.Time, ttl time.Duration, threshold int) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var next time.Duration
+	for _, entry := range c.entries {
+		wait := entry.removed.Add(contestBackoff(entry.count, threshold, ttl)).Sub(now)
+		if wait > 0 && (next == 0 || wait < next) {
+			next = wait
+		}
+	}
+	return next
+}
+
+// uncontested reports whether the taint may be removed from the node, that
+// is it is not backing off after being contested. Taints are never
+// contested when ContestThreshold is zero.
+func (r *TaintRemoverReconciler) uncontested(node *corev1.Node, target *removeTarget) bool {
+	if r.ContestThreshold <= 0 {
+		return true
+	}
+	return !r.contests.backingOff(node.Name, &target.Taint, r.currentTime(), r.ContestTTL, r.ContestThreshold)
+}
+
+// recordContests records the taints removed from the node, emitting a
+// Warning event on the node for each taint that becomes contested.
+func (r *TaintRemoverReconciler) recordContests(node *corev1.Node, removed []*corev1.Taint) {
+	if r.ContestThreshold <= 0 {
+		return
+	}
+	now := r.currentTime()
+	for _, t := range removed {
+		count := r.contests.recordRemoval(node.Name, t, now, r.ContestTTL, r.ContestThreshold)
+		if count < r.ContestThreshold {
+			continue
+		}
+		backoff := contestBackoff(count, r.ContestThreshold, r.ContestTTL)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(nodeReference(node), corev1.EventTypeWarning, "TaintContested",
+				"Taint %s was removed %d times in a row within %s, keeping it for %s",
+				t.ToString(), count, r.ContestTTL, backoff)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("Contested taints", func() {
+	var (
+		ctx        context.Context
+		now        time.Time
+		taint      corev1.Taint
+		targets    []*removeTarget
+		recorder   *record.FakeRecorder
+		reconciler *TaintRemoverReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		now = time.Now()
+		taint = corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		targets = []*removeTarget{{Taint: taint}}
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &TaintRemoverReconciler{
+			Client: newFakeClient(&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+			}),
+			Recorder:         recorder,
+			ContestThreshold: 2,
+			ContestTTL:       time.Minute,
+			now:              func() time.Time { return now },
+		}
+	})
+
+	// sweep removes the taints from the node and returns the number of
+	// patched nodes.
+	sweep := func() int {
+		node := &corev1.Node{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "test-node"}, node)).To(Succeed())
+		result, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		return result.NodesPatched
+	}
+	// readd adds the taint back to the node after the delay.
+	readd := func(delay time.Duration) {
+		now = now.Add(delay)
+		node := &corev1.Node{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "test-node"}, node)).To(Succeed())
+		node.Spec.Taints = append(node.Spec.Taints, taint)
+		Expect(reconciler.Update(ctx, node)).To(Succeed())
+	}
+
+	It("should back off from a taint added back right after its removal", func() {
+		Expect(sweep()).To(Equal(1))
+		readd(10 * time.Second)
+		Expect(sweep()).To(Equal(1))
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " TaintContested")))
+
+		readd(10 * time.Second)
+		Expect(sweep()).To(BeZero())
+		Expect(reconciler.nextRequeue()).To(Equal(50 * time.Second))
+
+		now = now.Add(50 * time.Second)
+		Expect(sweep()).To(Equal(1))
+		Expect(recorder.Events).To(Receive(ContainSubstring("keeping it for 2m0s")))
+
+		readd(time.Minute)
+		Expect(sweep()).To(BeZero())
+	})
+
+	It("should keep removing a taint added back after the TTL", func() {
+		for i := 0; i < 3; i++ {
+			Expect(sweep()).To(Equal(1))
+			readd(2 * time.Minute)
+		}
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should never back off when disabled", func() {
+		reconciler.ContestThreshold = 0
+		for i := 0; i < 3; i++ {
+			Expect(sweep()).To(Equal(1))
+			readd(time.Second)
+		}
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
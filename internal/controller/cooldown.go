@@ -0,0 +1,99 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// cooldownEntry records until when a patched node is cooled down.
+type cooldownEntry struct {
+	until time.Time
+	// skipped is set once a patch was skipped during the cooldown, so that
+	// the node is swept again when it ends.
+	skipped bool
+}
+
+// nodeCooldowns tracks the nodes patched within the cooldown so that a
+// flapping node is not patched again and again.
+type nodeCooldowns struct {
+	mu      sync.Mutex
+	entries map[string]cooldownEntry
+}
+
+// start cools the node down until the time.
+func (c *nodeCooldowns) start(node string, until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]cooldownEntry{}
+	}
+	c.entries[node] = cooldownEntry{until: until}
+}
+
+// skip reports whether the node is cooled down at now, and marks it skipped
+// if so.
+func (c *nodeCooldowns) skip(node string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[node]
+	if !ok || !now.Before(entry.until) {
+		return false
+	}
+	entry.skipped = true
+	c.entries[node] = entry
+	return true
+}
+
+// prune forgets the nodes whose cooldown has ended.
+func (c *nodeCooldowns) prune(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for node, entry := range c.entries {
+		if !now.Before(entry.until) {
+			delete(c.entries, node)
+		}
+	}
+}
+
+// next returns the time until the earliest cooldown of a skipped node ends,
+// or zero if no node was skipped.
+func (c *nodeCooldowns) next(now time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var next time.Duration
+	for _, entry := range c.entries {
+		wait := entry.until.Sub(now)
+		if entry.skipped && wait > 0 && (next == 0 || wait < next) {
+			next = wait
+		}
+	}
+	return next
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("node cooldown", func() {
+	var (
+		ctx        context.Context
+		now        time.Time
+		taint      corev1.Taint
+		targets    []*removeTarget
+		reconciler *TaintRemoverReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		now = time.Now()
+		taint = corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		targets = []*removeTarget{{Taint: taint}}
+		reconciler = &TaintRemoverReconciler{
+			Client: newFakeClient(&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+			}),
+			NodeCooldown: time.Minute,
+			now:          func() time.Time { return now },
+		}
+	})
+
+	// sweep removes the taints from the node and returns the number of
+	// patched nodes.
+	sweep := func() int {
+		node := &corev1.Node{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "test-node"}, node)).To(Succeed())
+		result, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		return result.NodesPatched
+	}
+	// retaint adds the taint back to the node after the delay.
+	retaint := func(delay time.Duration) {
+		now = now.Add(delay)
+		node := &corev1.Node{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "test-node"}, node)).To(Succeed())
+		node.Spec.Taints = append(node.Spec.Taints, taint)
+		Expect(reconciler.Update(ctx, node)).To(Succeed())
+	}
+
+	It("should skip a node patched within the cooldown", func() {
+		Expect(sweep()).To(Equal(1))
+		Expect(reconciler.nextRequeue()).To(BeZero())
+
+		retaint(10 * time.Second)
+		Expect(sweep()).To(BeZero())
+		Expect(reconciler.nextRequeue()).To(Equal(50 * time.Second))
+	})
+
+	It("should patch the node again once the cooldown has elapsed", func() {
+		Expect(sweep()).To(Equal(1))
+		retaint(10 * time.Second)
+		Expect(sweep()).To(BeZero())
+
+		now = now.Add(50 * time.Second)
+		Expect(sweep()).To(Equal(1))
+	})
+
+	It("should not cool nodes down when disabled", func() {
+		reconciler.NodeCooldown = 0
+		Expect(sweep()).To(Equal(1))
+		retaint(time.Second)
+		Expect(sweep()).To(Equal(1))
+	})
+
+	It("should forget the nodes whose cooldown has ended", func() {
+		Expect(sweep()).To(Equal(1))
+		now = now.Add(time.Minute)
+		reconciler.cooldowns.prune(now)
+		Expect(reconciler.cooldowns.entries).To(BeEmpty())
+	})
+})
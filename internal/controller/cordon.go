@@ -0,0 +1,98 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// cordonedNodes tracks the cordoned nodes whose unschedulable taint was
+// kept, so that the refusal is reported once per cordon rather than on every
+// evaluation.
+type cordonedNodes struct {
+	mu    sync.Mutex
+	nodes map[string]bool
+}
+
+// keep records that the unschedulable taint of the node was kept, and
+// reports whether it was not already since the node was cordoned.
+func (c *cordonedNodes) keep(node string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.nodes[node] {
+		return false
+	}
+	if c.nodes == nil {
+		c.nodes = make(map[string]bool)
+	}
+	c.nodes[node] = true
+	return true
+}
+
+// observe forgets the node once it is no longer cordoned.
+func (c *cordonedNodes) observe(node *corev1.Node) {
+	if !node.Spec.Unschedulable {
+		c.forget(node.Name)
+	}
+}
+
+// forget drops the node.
+func (c *cordonedNodes) forget(node string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.nodes, node)
+}
+
+// cordonFilter keeps the unschedulable taint of cordoned nodes, since the
+// node lifecycle controller adds it back as long as the node is cordoned.
+// A Warning event is emitted on the node when the removal is first refused
+// after the node was cordoned.
+// Uncordon targets may remove it, as they uncordon the node too.
+func (r *TaintRemoverReconciler) cordonFilter(ctx context.Context) removalFilter {
+	logger := log.FromContext(ctx)
+	return func(node *corev1.Node, target *removeTarget) bool {
+		if !node.Spec.Unschedulable || target.Uncordon ||
+			!strings.EqualFold(target.Taint.Key, corev1.TaintNodeUnschedulable) {
+			return true
+		}
+		if !r.cordoned.keep(node.Name) {
+			logger.V(1).Info("Keeping the unschedulable taint of a cordoned node", "node", node.Name,
+				"taint", target.Taint.ToString())
+			return false
+		}
+		logger.Info("Keeping the unschedulable taint of a cordoned node", "node", node.Name,
+			"taint", target.Taint.ToString())
+		if r.Recorder != nil {
+			r.Recorder.Eventf(nodeReference(node), corev1.EventTypeWarning, "NodeCordoned",
+				"Keeping taint %s: the node is cordoned", target.Taint.ToString())
+		}
+		return false
+	}
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("unschedulable taint", func() {
+	var (
+		unschedulable corev1.Taint
+		other         corev1.Taint
+		node          *corev1.Node
+		targets       []*removeTarget
+	)
+
+	BeforeEach(func() {
+		unschedulable = corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}
+		other = corev1.Taint{Key: "other", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{unschedulable, other}},
+		}
+		targets = []*removeTarget{{Taint: unschedulable}, {Taint: other}}
+	})
+
+	It("should keep the taint of a cordoned node", func() {
+		node.Spec.Unschedulable = true
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(node), Recorder: recorder}
+		result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.TaintsRemoved).To(Equal(1))
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{unschedulable}))
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " NodeCordoned")))
+	})
+
+	It("should warn once per cordon", func() {
+		node.Spec.Unschedulable = true
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(node), Recorder: recorder}
+		sweep := func() {
+			_, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node.DeepCopy()}, targets)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		sweep()
+		sweep()
+		Expect(recorder.Events).To(HaveLen(1))
+		<-recorder.Events
+
+		node.Spec.Unschedulable = false
+		sweep()
+		node.Spec.Unschedulable = true
+		sweep()
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " NodeCordoned")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should remove the taint of an uncordoned node", func() {
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(node)}
+		result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.TaintsRemoved).To(Equal(2))
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Taints).To(BeEmpty())
+	})
+})
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("log correlation", func() {
+	var (
+		ctx   context.Context
+		lines []string
+		node  *corev1.Node
+		tr    *nodesv1alpha1.TaintRemover
+	)
+
+	BeforeEach(func() {
+		lines = nil
+		logger := funcr.New(func(prefix, args string) {
+			lines = append(lines, args)
+		}, funcr.Options{})
+		ctx = log.IntoContext(context.TODO(), logger)
+		taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		tr = &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}},
+		}
+	})
+
+	idPattern := regexp.MustCompile(`"` + correlationKey + `"="([^"]+)"`)
+
+	// correlationIDs returns the correlation id of each log line, failing
+	// when a line has none.
+	correlationIDs := func() []string {
+		Expect(lines).NotTo(BeEmpty())
+		var ids []string
+		for _, line := range lines {
+			m := idPattern.FindStringSubmatch(line)
+			Expect(m).To(HaveLen(2), "line without correlation id: %s", line)
+			ids = append(ids, m[1])
+		}
+		return ids
+	}
+
+	It("should tag the log lines of a reconcile with a shared id", func() {
+		reconciler := newFakeReconciler(node, tr)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		first := correlationIDs()
+		Expect(first).To(HaveEach(first[0]))
+
+		lines = nil
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(correlationIDs()).To(HaveEach(Not(Equal(first[0]))))
+	})
+
+	It("should tag the log lines of a node event with a shared id", func() {
+		reconciler := newFakeReconciler(node, tr)
+		Expect(reconciler.applyTaintRemoveOnNode(ctx, node)).To(Succeed())
+		ids := correlationIDs()
+		Expect(ids).To(HaveEach(ids[0]))
+	})
+})
@@ -0,0 +1,58 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// missingCRDRequeue is the interval at which the removers are looked up
+// again while the TaintRemover CRD is not installed.
+const missingCRDRequeue = 5 * time.Minute
+
+// isMissingCRD reports whether the error means that the TaintRemover CRD is
+// not installed in the cluster.
+func isMissingCRD(err error) bool {
+	return meta.IsNoMatchError(err) || errors.IsNotFound(err)
+}
+
+// checkMissingCRD reports whether the error means that the TaintRemover CRD
+// is not installed. It logs that only once until the CRD shows up again.
+func (r *TaintRemoverReconciler) checkMissingCRD(ctx context.Context, err error) bool {
+	if err == nil || !isMissingCRD(err) {
+		r.crdMissing.Store(false)
+		return false
+	}
+	if !r.crdMissing.Swap(true) {
+		log.FromContext(ctx).Info("TaintRemover CRD is not installed, install it to enable taint removal",
+			"retry", missingCRDRequeue)
+	}
+	return true
+}
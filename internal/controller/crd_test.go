@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Missing CRD", func() {
+	It("should back off and log only once", func() {
+		var lines []string
+		logger := funcr.New(func(prefix, args string) {
+			lines = append(lines, args)
+		}, funcr.Options{})
+		ctx := log.IntoContext(context.TODO(), logger)
+
+		reconciler := &TaintRemoverReconciler{Client: newMissingCRDClient()}
+		for i := 0; i < 2; i++ {
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(missingCRDRequeue))
+		}
+		Expect(strings.Count(strings.Join(lines, "\n"), "CRD is not installed")).To(Equal(1))
+		Expect(strings.Join(lines, "\n")).NotTo(ContainSubstring("error"))
+	})
+
+	It("should log again after the CRD was installed", func() {
+		reconciler := &TaintRemoverReconciler{}
+		Expect(reconciler.checkMissingCRD(context.TODO(), &meta.NoKindMatchError{})).To(BeTrue())
+		Expect(reconciler.checkMissingCRD(context.TODO(), nil)).To(BeFalse())
+		Expect(reconciler.crdMissing.Load()).To(BeFalse())
+	})
+})
@@ -0,0 +1,83 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+const (
+	// createRetryDelay is the interval between the sweeps retried for a node
+	// created before the TaintRemovers got cached.
+	createRetryDelay = 2 * time.Second
+	// maxCreateRetries bounds the sweeps retried for such a node.
+	maxCreateRetries = 5
+)
+
+// removersNotCached reports whether TaintRemovers exist in the cluster while
+// none is cached yet, as when a node joins right after the controller
+// started. It is false without an API reader to compare with.
+func (r *TaintRemoverReconciler) removersNotCached(ctx context.Context) bool {
+	if r.APIReader == nil {
+		return false
+	}
+	cached := &nodesv1alpha1.TaintRemoverList{}
+	if err := r.List(ctx, cached, r.removerListOptions()...); err != nil || len(cached.Items) > 0 {
+		return false
+	}
+	live := &nodesv1alpha1.TaintRemoverList{}
+	opts := append(r.removerListOptions(), client.Limit(1))
+	if err := r.APIReader.List(ctx, live, opts...); err != nil {
+		log.FromContext(ctx).V(2).Info("unable to list TaintRemovers", "error", err.Error())
+		return false
+	}
+	return len(live.Items) > 0
+}
+
+// scheduleCreateRetries arms the bounded sweeps retried until the
+// TaintRemovers are cached.
+func (r *TaintRemoverReconciler) scheduleCreateRetries() {
+	r.createRetries.Store(maxCreateRetries)
+}
+
+// takeCreateRetry consumes one of the retries armed by scheduleCreateRetries.
+// It returns false when none is left.
+func (r *TaintRemoverReconciler) takeCreateRetry() bool {
+	for {
+		n := r.createRetries.Load()
+		if n < 1 {
+			return false
+		}
+		if r.createRetries.CompareAndSwap(n, n-1) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("node created before the removers are cached", func() {
+	var (
+		ctx     context.Context
+		join    corev1.Taint
+		node    *corev1.Node
+		remover *nodesv1alpha1.TaintRemover
+		q       workqueue.RateLimitingInterface
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		join = corev1.Taint{Key: "example.com/joining", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{join}},
+		}
+		remover = &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{join}},
+		}
+		q = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		DeferCleanup(q.ShutDown)
+	})
+
+	nodeTaints := func(c client.Client) []corev1.Taint {
+		found := &corev1.Node{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, found)).To(Succeed())
+		return found.Spec.Taints
+	}
+
+	It("should retry until the removers are cached", func() {
+		cached := newFakeClient(node)
+		r := &TaintRemoverReconciler{Client: cached, APIReader: newFakeClient(remover.DeepCopy())}
+		nh := &nodeHandler{r: r}
+
+		nh.Create(ctx, event.CreateEvent{Object: node}, q)
+		Expect(nodeTaints(cached)).To(Equal([]corev1.Taint{join}))
+		Eventually(q.Len, 2*createRetryDelay, 100*time.Millisecond).Should(Equal(1))
+
+		result, err := r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(createRetryDelay))
+
+		Expect(cached.Create(ctx, remover.DeepCopy())).To(Succeed())
+		result, err = r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(nodeTaints(cached)).To(BeEmpty())
+	})
+
+	It("should stop retrying after the bound", func() {
+		r := &TaintRemoverReconciler{Client: newFakeClient(node), APIReader: newFakeClient(remover.DeepCopy())}
+		r.scheduleCreateRetries()
+		for range maxCreateRetries {
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(createRetryDelay))
+		}
+		result, err := r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+
+	It("should not retry when no remover exists", func() {
+		r := &TaintRemoverReconciler{Client: newFakeClient(node), APIReader: newFakeClient()}
+		(&nodeHandler{r: r}).Create(ctx, event.CreateEvent{Object: node}, q)
+
+		result, err := r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+})
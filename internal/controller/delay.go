@@ -0,0 +1,151 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// delayEntry records when a pending taint on a node becomes removable.
+type delayEntry struct {
+	node  string
+	taint corev1.Taint
+	due   time.Time
+}
+
+// removalDelays tracks when matching taints were first observed on nodes so
+// that they are removed only after the remover's delay has elapsed.
+type removalDelays struct {
+	mu      sync.Mutex
+	entries map[string]delayEntry
+}
+
+// nodeTaintKey returns the key of the taint on the node.
+func nodeTaintKey(node string, taint *corev1.Taint) string {
+	return node + "/" + taint.Key + ":" + string(taint.Effect)
+}
+
+// delayKey returns the key of the taint on the node for the remover. Each
+// remover has its own entry, as removers may have different delays.
+func delayKey(node, remover string, taint *corev1.Taint) string {
+	return nodeTaintKey(node, taint) + "@" + remover
+}
+
+// observe records the taint on the node for the remover if it was not seen
+// yet and returns the time at which it becomes removable.
+func (d *removalDelays) observe(node, remover string, taint *corev1.Taint, delay time.Duration,
+	now time.Time) time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.entries == nil {
+		d.entries = map[string]delayEntry{}
+	}
+	key := delayKey(node, remover, taint)
+	entry, ok := d.entries[key]
+	if !ok {
+		entry = delayEntry{node: node, taint: *taint, due: now.Add(delay)}
+		d.entries[key] = entry
+	}
+	return entry.due
+}
+
+// prune forgets the entries of the node whose taints are no longer present.
+func (d *removalDelays) prune(node *corev1.Node) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	taints := tutil.NewTaintIndex(node.Spec.Taints)
+	for key, entry := range d.entries {
+		if entry.node == node.Name && !taints.Contains(&entry.taint) {
+			delete(d.entries, key)
+		}
+	}
+}
+
+// retain forgets the entries of the nodes not in the list, such as the
+// deleted nodes and the nodes with no taint left.
+func (d *removalDelays) retain(nodes []*corev1.Node) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	listed := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		listed[n.Name] = true
+	}
+	for key, entry := range d.entries {
+		if !listed[entry.node] {
+			delete(d.entries, key)
+		}
+	}
+}
+
+// forget forgets the entries of the deleted node.
+func (d *removalDelays) forget(node string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key, entry := range d.entries {
+		if entry.node == node {
+			delete(d.entries, key)
+		}
+	}
+}
+
+// next returns the time until the earliest pending taint becomes removable,
+// or zero if there is no pending taint.
+func (d *removalDelays) next(now time.Time) time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var next time.Duration
+	for _, entry := range d.entries {
+		wait := entry.due.Sub(now)
+		if wait > 0 && (next == 0 || wait < next) {
+			next = wait
+		}
+	}
+	return next
+}
+
+// delayElapsed reports whether the target's removal delay has elapsed since
+// the taint was first observed on the node. PreferNoSchedule taints of an
+// aggressive target are never delayed.
+func (r *TaintRemoverReconciler) delayElapsed(node *corev1.Node, target *removeTarget) bool {
+	if target.RemovalDelay == nil || target.RemovalDelay.Duration <= 0 {
+		return true
+	}
+	if target.AggressivePreferNoSchedule && target.Taint.Effect == corev1.TaintEffectPreferNoSchedule {
+		return true
+	}
+	now := r.currentTime()
+	due := r.delays.observe(node.Name, target.remover, &target.Taint, target.RemovalDelay.Duration, now)
+	return !now.Before(due)
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("RemovalDelay", func() {
+	var (
+		ctx        context.Context
+		now        time.Time
+		c          client.Client
+		node       *corev1.Node
+		reconciler *TaintRemoverReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		taint := corev1.Taint{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				Taints:       []corev1.Taint{taint},
+				RemovalDelay: &metav1.Duration{Duration: time.Minute},
+			},
+		}
+		c = newFakeClient(node, tr)
+		reconciler = &TaintRemoverReconciler{Client: c, now: func() time.Time { return now }}
+	})
+
+	It("should requeue a taint younger than the delay", func() {
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+
+		now = now.Add(20 * time.Second)
+		result, err = reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(40 * time.Second))
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(HaveLen(1))
+	})
+
+	It("should remove a taint older than the delay", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+
+		now = now.Add(2 * time.Minute)
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(BeEmpty())
+	})
+
+	It("should forget taints no longer present on the node", func() {
+		d := &removalDelays{}
+		taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		d.observe(node.Name, "test-taint-remover", &taint, time.Minute, now)
+		Expect(d.next(now)).To(Equal(time.Minute))
+
+		d.prune(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: node.Name}})
+		Expect(d.next(now)).To(BeZero())
+	})
+
+	It("should apply the delay of each remover to its own removal", func() {
+		taint := node.Spec.Taints[0]
+		slow := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-slow-taint-remover"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				Taints:       []corev1.Taint{taint},
+				RemovalDelay: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+		}
+		Expect(c.Create(ctx, slow)).To(Succeed())
+
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+
+		now = now.Add(90 * time.Second)
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(BeEmpty())
+	})
+
+	It("should forget the taints of deleted nodes", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.delays.entries).To(HaveLen(1))
+
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+		(&nodeHandler{r: reconciler}).Delete(ctx, event.DeleteEvent{Object: node}, q)
+		Expect(reconciler.delays.entries).To(BeEmpty())
+	})
+
+	It("should forget the taints of nodes no longer listed", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.delays.entries).To(HaveLen(1))
+
+		Expect(c.Delete(ctx, node)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.delays.entries).To(BeEmpty())
+	})
+})
+
+var _ = Describe("AggressivePreferNoSchedule", func() {
+	It("should only bypass the delay for PreferNoSchedule taints", func() {
+		ctx := context.TODO()
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		soft := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectPreferNoSchedule}
+		hard := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{soft, hard}},
+		}
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				Taints:                     []corev1.Taint{soft, hard},
+				RemovalDelay:               &metav1.Duration{Duration: time.Minute},
+				AggressivePreferNoSchedule: true,
+			},
+		}
+		c := newFakeClient(node, tr)
+		reconciler := &TaintRemoverReconciler{Client: c, now: func() time.Time { return now }}
+
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{hard}))
+	})
+})
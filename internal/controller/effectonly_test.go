@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("EffectOnlyAnnotation", func() {
+	var (
+		ctx        context.Context
+		spot       corev1.Taint
+		gpu        corev1.Taint
+		preferred  corev1.Taint
+		cordoned   corev1.Taint
+		effectOnly corev1.Taint
+		node       *corev1.Node
+		recorder   *record.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		spot = corev1.Taint{Key: "example.com/spot", Effect: corev1.TaintEffectNoSchedule}
+		gpu = corev1.Taint{Key: "gpu", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+		preferred = corev1.Taint{Key: "example.com/spot", Effect: corev1.TaintEffectPreferNoSchedule}
+		cordoned = corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}
+		effectOnly = corev1.Taint{Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{spot, gpu, preferred, cordoned}},
+		}
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	reconcileWith := func(annotations map[string]string) []corev1.Taint {
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover", Annotations: annotations},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{effectOnly}},
+		}
+		c := newFakeClient(node, tr)
+		_, err := (&TaintRemoverReconciler{Client: c, Recorder: recorder}).Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		return node.Spec.Taints
+	}
+
+	It("should remove the unprotected taints with the effect when opted in", func() {
+		taints := reconcileWith(map[string]string{nodesv1alpha1.EffectOnlyAnnotation: "true"})
+		Expect(taints).To(Equal([]corev1.Taint{preferred, cordoned}))
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeNormal + " TaintsRemoved")))
+	})
+
+	It("should be inert without the opt-in", func() {
+		Expect(reconcileWith(nil)).To(Equal([]corev1.Taint{spot, gpu, preferred, cordoned}))
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " InvalidTaint")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should be inert with another annotation value", func() {
+		taints := reconcileWith(map[string]string{nodesv1alpha1.EffectOnlyAnnotation: "yes"})
+		Expect(taints).To(Equal([]corev1.Taint{spot, gpu, preferred, cordoned}))
+	})
+
+	It("should leave the taints with a key to match as usual", func() {
+		target := removeTarget{Taint: spot}
+		target.setEffectOnly(true)
+		Expect(target.effectOnly).To(BeFalse())
+		Expect(target.candidates(node, exactKeyMatch)).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("EmptyResultRequeue", func() {
+	var (
+		ctx  context.Context
+		node *corev1.Node
+		tr   *nodesv1alpha1.TaintRemover
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		node = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+		tr = &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{
+				{Key: "foo", Effect: corev1.TaintEffectNoSchedule},
+			}},
+		}
+	})
+
+	reconcileWith := func(requeue time.Duration, objs ...client.Object) ctrl.Result {
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(objs...), EmptyResultRequeue: requeue}
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	It("should requeue when removers exist but no node is tainted", func() {
+		Expect(reconcileWith(time.Minute, node, tr)).To(Equal(ctrl.Result{RequeueAfter: time.Minute}))
+	})
+
+	It("should not requeue when disabled", func() {
+		Expect(reconcileWith(0, node, tr)).To(Equal(ctrl.Result{}))
+	})
+
+	It("should not requeue without removers", func() {
+		Expect(reconcileWith(time.Minute, node)).To(Equal(ctrl.Result{}))
+	})
+})
@@ -0,0 +1,91 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// TaintsEnvVar is the environment variable listing the taints to remove
+// with --taints-from-env, as comma or newline separated taint specs.
+const TaintsEnvVar = "TAINT_REMOVER_TAINTS"
+
+// envSource tells whether the taints listed in TaintsEnvVar are removed and
+// whether their values are URL-decoded.
+type envSource struct {
+	enabled      bool
+	decodeValues bool
+}
+
+// envSource returns the source of the taints listed in TaintsEnvVar.
+func (r *TaintRemoverReconciler) envSource() envSource {
+	return envSource{enabled: r.TaintsFromEnv, decodeValues: r.DecodeTaintValues}
+}
+
+// taintsFromEnv parses the taints listed in TaintsEnvVar. Both the
+// "key:effect" and "key:effect-" forms select a taint to remove.
+func taintsFromEnv(src envSource) ([]corev1.Taint, error) {
+	specs := strings.FieldsFunc(os.Getenv(TaintsEnvVar), func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+	var trimmed []string
+	for _, spec := range specs {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			trimmed = append(trimmed, spec)
+		}
+	}
+	if len(trimmed) < 1 {
+		return nil, nil
+	}
+	var opts []tutil.ParseOption
+	if src.decodeValues {
+		opts = append(opts, tutil.DecodeValues())
+	}
+	taints, toRemove, err := tutil.ParseTaints(trimmed, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return append(taints, toRemove...), nil
+}
+
+// envTargets returns the remove targets for the taints listed in
+// TaintsEnvVar. They have no restriction and are reported under the
+// variable name in place of a remover name.
+func envTargets(src envSource) ([]removeTarget, error) {
+	taints, err := taintsFromEnv(src)
+	if err != nil {
+		return nil, err
+	}
+	var targets []removeTarget
+	for _, t := range taints {
+		targets = append(targets, removeTarget{Taint: t, remover: TaintsEnvVar})
+	}
+	return targets, nil
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+var _ = Describe("TaintsFromEnv", func() {
+	var (
+		ctx    context.Context
+		envA   corev1.Taint
+		envB   corev1.Taint
+		listed corev1.Taint
+		node   *corev1.Node
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		envA = corev1.Taint{Key: "example.com/env-a", Value: "x", Effect: corev1.TaintEffectNoSchedule}
+		envB = corev1.Taint{Key: "example.com/env-b", Effect: corev1.TaintEffectPreferNoSchedule}
+		listed = corev1.Taint{Key: "example.com/listed", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{envA, envB, listed}},
+		}
+		prev, set := os.LookupEnv(TaintsEnvVar)
+		DeferCleanup(func() {
+			if set {
+				os.Setenv(TaintsEnvVar, prev)
+			} else {
+				os.Unsetenv(TaintsEnvVar)
+			}
+		})
+	})
+
+	reconcileNodeWith := func(reconciler *TaintRemoverReconciler) []corev1.Taint {
+		c := reconciler.Client
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		found := &corev1.Node{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, found)).To(Succeed())
+		return found.Spec.Taints
+	}
+	reconcileNode := func(c client.Client) []corev1.Taint {
+		return reconcileNodeWith(&TaintRemoverReconciler{Client: c, TaintsFromEnv: true})
+	}
+
+	It("should merge the taints from the environment with the removers", func() {
+		Expect(os.Setenv(TaintsEnvVar, "example.com/env-a:NoSchedule,\nexample.com/env-b:PreferNoSchedule-\n")).To(Succeed())
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{listed}},
+		}
+		Expect(reconcileNode(newFakeClient(node, tr))).To(BeEmpty())
+	})
+
+	It("should remove the taints from the environment without the CRD", func() {
+		Expect(os.Setenv(TaintsEnvVar, "example.com/env-a:NoSchedule")).To(Succeed())
+		Expect(reconcileNode(newMissingCRDClient(node))).To(Equal([]corev1.Taint{envB, listed}))
+	})
+
+	It("should remove only the remover taints when the variable is unset", func() {
+		Expect(os.Unsetenv(TaintsEnvVar)).To(Succeed())
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{listed}},
+		}
+		Expect(reconcileNode(newFakeClient(node, tr))).To(Equal([]corev1.Taint{envA, envB}))
+	})
+
+	It("should skip invalid taint specs", func() {
+		Expect(os.Setenv(TaintsEnvVar, "example.com/env-a")).To(Succeed())
+		Expect(reconcileNode(newFakeClient(node))).To(HaveLen(3))
+	})
+
+	It("should take the values literally unless decoding is enabled", func() {
+		Expect(os.Setenv(TaintsEnvVar, "example.com/env-a=x%2Ey:NoSchedule")).To(Succeed())
+		Expect(reconcileNode(newFakeClient(node))).To(HaveLen(3))
+
+		taints, err := taintsFromEnv(envSource{enabled: true, decodeValues: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(taints).To(Equal([]corev1.Taint{{Key: "example.com/env-a", Value: "x.y", Effect: corev1.TaintEffectNoSchedule}}))
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(node), TaintsFromEnv: true, DecodeTaintValues: true}
+		Expect(reconcileNodeWith(reconciler)).To(Equal([]corev1.Taint{envB, listed}))
+	})
+
+	It("should reject the values that are not valid taint values once decoded", func() {
+		Expect(os.Setenv(TaintsEnvVar, "example.com/env-a=a%2Fb:NoSchedule")).To(Succeed())
+		_, err := taintsFromEnv(envSource{enabled: true, decodeValues: true})
+		Expect(err).To(MatchError(tutil.ErrTaintValue))
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(node), TaintsFromEnv: true, DecodeTaintValues: true}
+		Expect(reconcileNodeWith(reconciler)).To(HaveLen(3))
+	})
+})
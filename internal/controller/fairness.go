@@ -0,0 +1,78 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"slices"
+)
+
+// fairOrder orders the patches round-robin across the removers requesting
+// them, so that a remover selecting many nodes does not delay the nodes of
+// the others until its sweep completes. Removers take turns in the order of
+// their first target, and a node requested by several removers is patched
+// on the first turn reaching it.
+func fairOrder(patches []nodePatchSpec, taints []*removeTarget, removals taintRemovals) []nodePatchSpec {
+	var removers []string
+	for _, t := range taints {
+		if !slices.Contains(removers, t.remover) {
+			removers = append(removers, t.remover)
+		}
+	}
+	queues := make(map[string][]int, len(removers))
+	for i, p := range patches {
+		var seen []string
+		for _, e := range removals[p.node.Name] {
+			if !slices.Contains(seen, e.remover) {
+				seen = append(seen, e.remover)
+				queues[e.remover] = append(queues[e.remover], i)
+			}
+		}
+	}
+
+	done := make([]bool, len(patches))
+	result := make([]nodePatchSpec, 0, len(patches))
+	for progressed := true; progressed; {
+		progressed = false
+		for _, name := range removers {
+			queue := queues[name]
+			for len(queue) > 0 && done[queue[0]] {
+				queue = queue[1:]
+			}
+			if len(queue) > 0 {
+				done[queue[0]] = true
+				result = append(result, patches[queue[0]])
+				queue = queue[1:]
+				progressed = true
+			}
+			queues[name] = queue
+		}
+	}
+	for i, p := range patches {
+		if !done[i] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
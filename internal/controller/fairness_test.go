@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("fairness", func() {
+	It("should not let a large remover delay a small one until its sweep completes", func() {
+		large := corev1.Taint{Key: "large", Effect: corev1.TaintEffectNoSchedule}
+		small := corev1.Taint{Key: "small", Effect: corev1.TaintEffectNoSchedule}
+		objs := []client.Object{
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "large"},
+				Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{large}},
+			},
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "small"},
+				Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{small}},
+			},
+			&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "z-small"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{small}},
+			},
+		}
+		for i := 0; i < 5; i++ {
+			objs = append(objs, &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("a-large-%d", i)},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{large}},
+			})
+		}
+
+		var order []string
+		c := &erroringClient{
+			Client: newFakeClient(objs...),
+			patchErr: func(obj client.Object) error {
+				if _, ok := obj.(*corev1.Node); ok {
+					order = append(order, obj.GetName())
+				}
+				return nil
+			},
+		}
+		reconciler := &TaintRemoverReconciler{Client: c}
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(order).To(HaveLen(6))
+		Expect(order[:2]).To(ConsistOf("a-large-0", "z-small"))
+	})
+})
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// newFakeClient returns a fake client that knows the TaintRemover types and
+// holds the given objects.
+func newFakeClient(objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	Expect(corev1.AddToScheme(s)).To(Succeed())
+	Expect(appsv1.AddToScheme(s)).To(Succeed())
+	Expect(policyv1.AddToScheme(s)).To(Succeed())
+	Expect(nodesv1alpha1.AddToScheme(s)).To(Succeed())
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).
+		WithStatusSubresource(&nodesv1alpha1.TaintRemover{}).
+		WithIndex(&corev1.Pod{}, podNodeNameField, func(o client.Object) []string {
+			return []string{o.(*corev1.Pod).Spec.NodeName}
+		}).
+		WithIndex(&corev1.Node{}, nodeLabelField, nodeLabelValues).Build()
+}
+
+// newFakeReconciler returns a reconciler backed by a fake client holding the
+// given objects.
+func newFakeReconciler(objs ...client.Object) *TaintRemoverReconciler {
+	return &TaintRemoverReconciler{Client: newFakeClient(objs...)}
+}
+
+// erroringClient is a client whose List and Patch fail with the error
+// returned by its hooks. The call is passed through when the hook is nil or
+// returns nil.
+type erroringClient struct {
+	client.Client
+	listErr  func(list client.ObjectList) error
+	patchErr func(obj client.Object) error
+}
+
+func (c *erroringClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if c.listErr != nil {
+		if err := c.listErr(list); err != nil {
+			return err
+		}
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *erroringClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if c.patchErr != nil {
+		if err := c.patchErr(obj); err != nil {
+			return err
+		}
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// newForbiddenListClient returns a client whose List is forbidden.
+func newForbiddenListClient(objs ...client.Object) client.Client {
+	return &erroringClient{
+		Client: newFakeClient(objs...),
+		listErr: func(client.ObjectList) error {
+			return apierrors.NewForbidden(schema.GroupResource{Resource: "taintremovers"}, "", nil)
+		},
+	}
+}
+
+// newForbiddenPatchClient returns a client whose Patch is forbidden.
+func newForbiddenPatchClient(objs ...client.Object) client.Client {
+	return &erroringClient{
+		Client: newFakeClient(objs...),
+		patchErr: func(obj client.Object) error {
+			return apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, obj.GetName(), nil)
+		},
+	}
+}
+
+// newMissingCRDClient returns a client for a cluster without the
+// TaintRemover CRD.
+func newMissingCRDClient(objs ...client.Object) client.Client {
+	return &erroringClient{
+		Client: newFakeClient(objs...),
+		listErr: func(list client.ObjectList) error {
+			if _, ok := list.(*nodesv1alpha1.TaintRemoverList); !ok {
+				return nil
+			}
+			return &meta.NoKindMatchError{
+				GroupKind:        nodesv1alpha1.GroupVersion.WithKind("TaintRemover").GroupKind(),
+				SearchedVersions: []string{nodesv1alpha1.GroupVersion.Version},
+			}
+		},
+	}
+}
+
+// newConflictOnceClient returns a client whose first Patch fails with a
+// conflict, as when the object was modified concurrently.
+func newConflictOnceClient(objs ...client.Object) client.Client {
+	var conflicted atomic.Bool
+	return &erroringClient{
+		Client: newFakeClient(objs...),
+		patchErr: func(obj client.Object) error {
+			if conflicted.Swap(true) {
+				return nil
+			}
+			return apierrors.NewConflict(schema.GroupResource{Resource: "nodes"}, obj.GetName(),
+				errors.New("the object has been modified"))
+		},
+	}
+}
+
+// fakeCache is a cache whose WaitForCacheSync blocks until synced is closed.
+// Like the informer cache, it checks synced once even on a done context.
+type fakeCache struct {
+	cache.Cache
+	synced chan struct{}
+}
+
+func (f *fakeCache) WaitForCacheSync(ctx context.Context) bool {
+	select {
+	case <-f.synced:
+		return true
+	default:
+	}
+	select {
+	case <-f.synced:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// countingClient is a client that counts List calls and node Patch calls.
+type countingClient struct {
+	client.Client
+	lists   atomic.Int32
+	patches atomic.Int32
+}
+
+func (c *countingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := obj.(*corev1.Node); ok {
+		c.patches.Add(1)
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *countingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.lists.Add(1)
+	return c.Client.List(ctx, list, opts...)
+}
+
+// blockingPatchClient is a client whose Patch blocks for the node named
+// block until the context is done.
+type blockingPatchClient struct {
+	client.Client
+	block string
+}
+
+func (c *blockingPatchClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if obj.GetName() == c.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// recordingPatchClient is a client that records the type and data of the
+// node patches.
+type recordingPatchClient struct {
+	client.Client
+	types []types.PatchType
+	data  [][]byte
+}
+
+func (c *recordingPatchClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := obj.(*corev1.Node); ok {
+		data, err := patch.Data(obj)
+		if err != nil {
+			return err
+		}
+		c.types = append(c.types, patch.Type())
+		c.data = append(c.data, data)
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// statusCountingClient is a client that counts the status updates, failing
+// them with err when it is set.
+type statusCountingClient struct {
+	client.Client
+	updates atomic.Int32
+	err     error
+}
+
+func (c *statusCountingClient) Status() client.SubResourceWriter {
+	return &countingStatusWriter{SubResourceWriter: c.Client.Status(), client: c}
+}
+
+type countingStatusWriter struct {
+	client.SubResourceWriter
+	client *statusCountingClient
+}
+
+func (w *countingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	w.client.updates.Add(1)
+	if w.client.err != nil {
+		return w.client.err
+	}
+	return w.SubResourceWriter.Update(ctx, obj, opts...)
+}
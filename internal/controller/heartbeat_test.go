@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("MaxHeartbeatStaleness", func() {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+
+	newNode := func(heartbeat time.Time) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: metav1.NewTime(heartbeat)},
+			}},
+		}
+	}
+
+	removeWith := func(node *corev1.Node, staleness time.Duration) int {
+		reconciler := newFakeReconciler(node)
+		reconciler.now = func() time.Time { return now }
+		reconciler.MaxHeartbeatStaleness = staleness
+		result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+		Expect(err).NotTo(HaveOccurred())
+		return result.TaintsRemoved
+	}
+
+	It("should remove the taints of a node with a fresh heartbeat", func() {
+		Expect(removeWith(newNode(now.Add(-time.Minute)), 5*time.Minute)).To(Equal(1))
+	})
+
+	It("should skip a node with a stale heartbeat", func() {
+		Expect(removeWith(newNode(now.Add(-10*time.Minute)), 5*time.Minute)).To(BeZero())
+	})
+
+	It("should skip a node without a heartbeat", func() {
+		node := newNode(now)
+		node.Status.Conditions = nil
+		Expect(removeWith(node, 5*time.Minute)).To(BeZero())
+	})
+
+	It("should not check the heartbeat when disabled", func() {
+		Expect(removeWith(newNode(now.Add(-time.Hour)), 0)).To(Equal(1))
+	})
+})
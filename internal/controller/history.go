@@ -0,0 +1,85 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	taintremover "github.com/norseto/taint-remover"
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// historyLimit is the number of removals kept in the history annotation.
+const historyLimit = 10
+
+// historyEntry records the removal of a taint from a node.
+type historyEntry struct {
+	Time  string `json:"time"`
+	Taint string `json:"taint"`
+}
+
+// nodeHistory returns the removals recorded in the node's history
+// annotation. A malformed annotation is treated as empty.
+func nodeHistory(node *corev1.Node) []historyEntry {
+	value := node.GetAnnotations()[nodesv1alpha1.HistoryAnnotation]
+	if value == "" {
+		return nil
+	}
+	var entries []historyEntry
+	if err := json.Unmarshal([]byte(value), &entries); err != nil {
+		return nil
+	}
+	return entries
+}
+
+// appendHistory returns the history annotation value of the node with the
+// removed taints appended. Only the latest historyLimit removals are kept.
+func appendHistory(node *corev1.Node, removed []*corev1.Taint, now time.Time) string {
+	entries := nodeHistory(node)
+	for _, t := range removed {
+		entries = append(entries, historyEntry{Time: now.UTC().Format(time.RFC3339), Taint: t.ToString()})
+	}
+	if len(entries) > historyLimit {
+		entries = entries[len(entries)-historyLimit:]
+	}
+	data, _ := json.Marshal(entries)
+	return string(data)
+}
+
+// lastPatch records the controller version and the time of a node patch.
+type lastPatch struct {
+	Version string `json:"version"`
+	Time    string `json:"time"`
+}
+
+// lastPatchValue returns the last patch annotation value for a patch made
+// at now by this controller version.
+func lastPatchValue(now time.Time) string {
+	data, _ := json.Marshal(lastPatch{Version: taintremover.Version, Time: now.UTC().Format(time.RFC3339)})
+	return string(data)
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	taintremover "github.com/norseto/taint-remover"
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("removal history", func() {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	It("should append the removed taints to the node history", func() {
+		ctx := context.TODO()
+		taint := corev1.Taint{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		reconciler := newFakeReconciler(node)
+		reconciler.now = func() time.Time { return now }
+		_, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(nodeHistory(node)).To(Equal([]historyEntry{{Time: "2024-01-01T00:00:00Z", Taint: "foo=bar:NoSchedule"}}))
+	})
+
+	It("should keep only the latest removals", func() {
+		var entries []historyEntry
+		for i := 0; i < historyLimit; i++ {
+			entries = append(entries, historyEntry{Time: "2023-01-01T00:00:00Z", Taint: fmt.Sprintf("old-%d:NoSchedule", i)})
+		}
+		data, err := json.Marshal(entries)
+		Expect(err).NotTo(HaveOccurred())
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{nodesv1alpha1.HistoryAnnotation: string(data)},
+		}}
+
+		value := appendHistory(node, []*corev1.Taint{
+			{Key: "new-0", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "new-1", Effect: corev1.TaintEffectNoSchedule},
+		}, now)
+		node.Annotations[nodesv1alpha1.HistoryAnnotation] = value
+		history := nodeHistory(node)
+		Expect(history).To(HaveLen(historyLimit))
+		Expect(history[0].Taint).To(Equal("old-2:NoSchedule"))
+		Expect(history[historyLimit-2:]).To(Equal([]historyEntry{
+			{Time: "2024-01-01T00:00:00Z", Taint: "new-0:NoSchedule"},
+			{Time: "2024-01-01T00:00:00Z", Taint: "new-1:NoSchedule"},
+		}))
+	})
+
+	It("should grow below the limit", func() {
+		node := &corev1.Node{}
+		node.Annotations = map[string]string{
+			nodesv1alpha1.HistoryAnnotation: appendHistory(node, []*corev1.Taint{{Key: "a", Effect: corev1.TaintEffectNoSchedule}}, now),
+		}
+		node.Annotations[nodesv1alpha1.HistoryAnnotation] =
+			appendHistory(node, []*corev1.Taint{{Key: "b", Effect: corev1.TaintEffectNoSchedule}}, now)
+		Expect(nodeHistory(node)).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("last patch annotation", func() {
+	It("should stamp the patched node with the version and time", func() {
+		ctx := context.TODO()
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		reconciler := newFakeReconciler(node)
+		reconciler.now = func() time.Time { return now }
+		_, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		var got lastPatch
+		Expect(json.Unmarshal([]byte(node.Annotations[nodesv1alpha1.LastPatchAnnotation]), &got)).To(Succeed())
+		Expect(got).To(Equal(lastPatch{Version: taintremover.Version, Time: "2024-01-01T00:00:00Z"}))
+	})
+
+	It("should not pass the node update predicate alone", func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+		stamped := node.DeepCopy()
+		stamped.Annotations = map[string]string{nodesv1alpha1.LastPatchAnnotation: lastPatchValue(time.Now())}
+		Expect((&TaintRemoverReconciler{}).nodeChanged(node, stamped)).To(BeFalse())
+	})
+})
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("idempotency", func() {
+	It("should not patch again when reconciled twice", func() {
+		ctx := context.TODO()
+		spot := corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoSchedule}
+		other := corev1.Taint{Key: "other", Effect: corev1.TaintEffectNoSchedule}
+		c := &countingClient{Client: newFakeClient(
+			&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "spot"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{spot, other}},
+			},
+			&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "other"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{other}},
+			},
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "spot"},
+				Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{spot}},
+			},
+		)}
+		reconciler := &TaintRemoverReconciler{Client: c}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.patches.Load()).To(Equal(int32(1)))
+
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.patches.Load()).To(Equal(int32(1)))
+	})
+})
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("ReconcileJitter", func() {
+	const jitter = 5 * time.Second
+
+	It("should add up to the jitter to the requeue", func() {
+		reconciler := &TaintRemoverReconciler{Client: newMissingCRDClient(), ReconcileJitter: jitter}
+		for range 20 {
+			result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">=", missingCRDRequeue))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", missingCRDRequeue+jitter))
+		}
+	})
+
+	It("should use the injected random source", func() {
+		var bound int64
+		reconciler := &TaintRemoverReconciler{
+			Client:          newMissingCRDClient(),
+			ReconcileJitter: jitter,
+			randInt64N: func(n int64) int64 {
+				bound = n
+				return n - 1
+			},
+		}
+		result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bound).To(Equal(int64(jitter) + 1))
+		Expect(result.RequeueAfter).To(Equal(missingCRDRequeue + jitter))
+	})
+
+	It("should not requeue when no sweep is needed", func() {
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(), ReconcileJitter: jitter}
+		result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+})
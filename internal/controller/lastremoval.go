@@ -0,0 +1,65 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// removalClock tracks the time of the last taint removal, from which the
+// taintremover_seconds_since_last_removal gauge is computed on collection.
+// Until a taint is removed, the time is the one the clock was created at.
+type removalClock struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	last time.Time
+}
+
+// newRemovalClock returns a removal clock telling the time with now.
+func newRemovalClock(now func() time.Time) *removalClock {
+	return &removalClock{now: now, last: now()}
+}
+
+// record records a taint removal at the current time.
+func (c *removalClock) record() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last = c.now()
+}
+
+// secondsSince returns the seconds elapsed since the last taint removal.
+func (c *removalClock) secondsSince() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now().Sub(c.last).Seconds()
+}
+
+// setClock replaces the function telling the time, for tests.
+func (c *removalClock) setClock(now func() time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
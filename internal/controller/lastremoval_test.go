@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("secondsSinceLastRemoval", func() {
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		lastRemoval.setClock(func() time.Time { return now })
+		DeferCleanup(lastRemoval.setClock, time.Now)
+	})
+
+	It("should report the time elapsed since the clock was created", func() {
+		clock := newRemovalClock(func() time.Time { return now })
+		now = now.Add(90 * time.Second)
+		Expect(clock.secondsSince()).To(Equal(90.0))
+		clock.record()
+		Expect(clock.secondsSince()).To(BeZero())
+	})
+
+	It("should reflect the time elapsed since the last removal", func() {
+		taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(node)}
+		result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.TaintsRemoved).To(Equal(1))
+		Expect(testutil.ToFloat64(secondsSinceLastRemoval)).To(BeZero())
+
+		now = now.Add(5 * time.Minute)
+		Expect(testutil.ToFloat64(secondsSinceLastRemoval)).To(Equal(300.0))
+
+		untainted := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "untainted"}}
+		result, err = reconciler.removeTaints(context.TODO(), []*corev1.Node{untainted}, []*removeTarget{{Taint: taint}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.TaintsRemoved).To(BeZero())
+		Expect(testutil.ToFloat64(secondsSinceLastRemoval)).To(Equal(300.0))
+	})
+})
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("Leader election", func() {
+	var (
+		ctx        context.Context
+		node       *corev1.Node
+		elected    chan struct{}
+		reconciler *TaintRemoverReconciler
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}},
+		}
+		elected = make(chan struct{})
+		reconciler = &TaintRemoverReconciler{Client: newFakeClient(node, tr), Elected: elected}
+	})
+
+	standby := func() bool {
+		rec := httptest.NewRecorder()
+		reconciler.StatusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+		status := sweepStatus{}
+		Expect(json.NewDecoder(rec.Body).Decode(&status)).To(Succeed())
+		return status.Standby
+	}
+
+	It("should start removing taints only after the election", func() {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Taints).To(HaveLen(1))
+		Expect(standby()).To(BeTrue())
+
+		close(elected)
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Taints).To(BeEmpty())
+		Expect(standby()).To(BeFalse())
+	})
+
+	It("should always sweep without leader election", func() {
+		reconciler.Elected = nil
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Spec.Taints).To(BeEmpty())
+	})
+})
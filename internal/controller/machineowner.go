@@ -0,0 +1,49 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultMachineOwnerAnnotationKey is the node annotation holding the owner
+// of the Cluster API Machine of the node when MachineOwnerAnnotationKey is
+// empty.
+const defaultMachineOwnerAnnotationKey = "cluster.x-k8s.io/owner-name"
+
+// machineOwnerKey returns the node annotation holding the machine owner.
+func (r *TaintRemoverReconciler) machineOwnerKey() string {
+	if r.MachineOwnerAnnotationKey == "" {
+		return defaultMachineOwnerAnnotationKey
+	}
+	return r.MachineOwnerAnnotationKey
+}
+
+// ownedByMachineOwner reports whether the node was created for the machine
+// owner of the target. Every node matches when the target has no machine
+// owner, including the nodes not managed by Cluster API.
+func (r *TaintRemoverReconciler) ownedByMachineOwner(node *corev1.Node, target *removeTarget) bool {
+	return target.MachineOwner == "" || node.Annotations[r.machineOwnerKey()] == target.MachineOwner
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("MachineOwner", func() {
+	var (
+		taint   corev1.Taint
+		workers *corev1.Node
+		infra   *corev1.Node
+		plain   *corev1.Node
+	)
+
+	newNode := func(name string, annotations map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+	}
+
+	BeforeEach(func() {
+		taint = corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		workers = newNode("workers", map[string]string{
+			"cluster.x-k8s.io/owner-name":   "md-workers",
+			"cluster.x-k8s.io/cluster-name": "prod",
+		})
+		infra = newNode("infra", map[string]string{
+			"cluster.x-k8s.io/owner-name":   "md-infra",
+			"cluster.x-k8s.io/cluster-name": "staging",
+		})
+		plain = newNode("plain", nil)
+	})
+
+	removeWith := func(reconciler *TaintRemoverReconciler, target *removeTarget) {
+		reconciler.Client = newFakeClient(workers, infra, plain)
+		nodes := []*corev1.Node{workers, infra, plain}
+		_, err := reconciler.removeTaints(context.TODO(), nodes, []*removeTarget{target})
+		Expect(err).NotTo(HaveOccurred())
+		for _, n := range nodes {
+			Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(n), n)).To(Succeed())
+		}
+	}
+
+	It("should remove the taints only from the nodes of the machine owner", func() {
+		removeWith(&TaintRemoverReconciler{}, &removeTarget{Taint: taint, MachineOwner: "md-workers"})
+		Expect(workers.Spec.Taints).To(BeEmpty())
+		Expect(infra.Spec.Taints).To(HaveLen(1))
+		Expect(plain.Spec.Taints).To(HaveLen(1))
+	})
+
+	It("should match the configured annotation key", func() {
+		removeWith(&TaintRemoverReconciler{MachineOwnerAnnotationKey: "cluster.x-k8s.io/cluster-name"},
+			&removeTarget{Taint: taint, MachineOwner: "staging"})
+		Expect(workers.Spec.Taints).To(HaveLen(1))
+		Expect(infra.Spec.Taints).To(BeEmpty())
+		Expect(plain.Spec.Taints).To(HaveLen(1))
+	})
+
+	It("should remove the taints from all nodes without a machine owner", func() {
+		removeWith(&TaintRemoverReconciler{}, &removeTarget{Taint: taint})
+		Expect(workers.Spec.Taints).To(BeEmpty())
+		Expect(infra.Spec.Taints).To(BeEmpty())
+		Expect(plain.Spec.Taints).To(BeEmpty())
+	})
+})
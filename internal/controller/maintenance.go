@@ -0,0 +1,106 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+
+const (
+	// maintenanceWindowStartKey is the ConfigMap key of the window start.
+	maintenanceWindowStartKey = "start"
+	// maintenanceWindowEndKey is the ConfigMap key of the window end.
+	maintenanceWindowEndKey = "end"
+)
+
+// maintenanceWindowActive reports whether now is within the window described
+// by the referenced ConfigMap.
+func maintenanceWindowActive(ctx context.Context, c client.Reader, ref *nodesv1alpha1.MaintenanceWindowReference,
+	now time.Time) (bool, error) {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cm)
+	if err != nil {
+		return false, err
+	}
+	if v, ok := cm.Data[maintenanceWindowStartKey]; ok {
+		start, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return false, fmt.Errorf("invalid maintenance window %s: %w", maintenanceWindowStartKey, err)
+		}
+		if now.Before(start) {
+			return false, nil
+		}
+	}
+	if v, ok := cm.Data[maintenanceWindowEndKey]; ok {
+		end, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return false, fmt.Errorf("invalid maintenance window %s: %w", maintenanceWindowEndKey, err)
+		}
+		if !now.Before(end) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// maintenanceFilter returns a removal filter that keeps the taints of
+// targets whose maintenance window is not active. Each window is looked up
+// once per sweep, and the sweep is marked for a retry when any removal is
+// kept.
+func (r *TaintRemoverReconciler) maintenanceFilter(ctx context.Context) removalFilter {
+	logger := log.FromContext(ctx)
+	active := make(map[nodesv1alpha1.MaintenanceWindowReference]bool)
+	r.maintenancePending.Store(false)
+	return func(_ *corev1.Node, target *removeTarget) bool {
+		ref := target.MaintenanceWindow
+		if ref == nil {
+			return true
+		}
+		open, seen := active[*ref]
+		if !seen {
+			var err error
+			open, err = maintenanceWindowActive(ctx, r.liveReader(), ref, r.currentTime())
+			if err != nil {
+				logger.Error(err, "Failed to get maintenance window", "namespace", ref.Namespace, "name", ref.Name)
+			}
+			active[*ref] = open
+		}
+		if !open {
+			r.maintenancePending.Store(true)
+		}
+		return open
+	}
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("MaintenanceWindow", func() {
+	var (
+		ctx     context.Context
+		now     time.Time
+		taint   corev1.Taint
+		node    *corev1.Node
+		remover *nodesv1alpha1.TaintRemover
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		taint = corev1.Taint{Key: "node.example.com/maintenance", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		remover = &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "maintenance"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				Taints:            []corev1.Taint{taint},
+				MaintenanceWindow: &nodesv1alpha1.MaintenanceWindowReference{Namespace: "kube-system", Name: "window"},
+			},
+		}
+	})
+
+	newWindow := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "window"},
+			Data:       data,
+		}
+	}
+
+	reconcileWith := func(objs ...client.Object) (reconcile.Result, []corev1.Taint) {
+		reconciler := newFakeReconciler(objs...)
+		reconciler.now = func() time.Time { return now }
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		return result, node.Spec.Taints
+	}
+
+	It("should remove the taint while the window is active", func() {
+		result, taints := reconcileWith(node, remover, newWindow(map[string]string{
+			"start": "2024-01-01T11:00:00Z", "end": "2024-01-01T13:00:00Z",
+		}))
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(taints).To(BeEmpty())
+	})
+
+	It("should remove the taint in a window without bounds", func() {
+		_, taints := reconcileWith(node, remover, newWindow(nil))
+		Expect(taints).To(BeEmpty())
+	})
+
+	DescribeTable("keeping the taint and requeueing while the window is not active",
+		func(window *corev1.ConfigMap) {
+			objs := []client.Object{node, remover}
+			if window != nil {
+				objs = append(objs, window)
+			}
+			result, taints := reconcileWith(objs...)
+			Expect(result.RequeueAfter).To(Equal(gatedRequeue))
+			Expect(taints).To(Equal([]corev1.Taint{taint}))
+		},
+		Entry("before the start", newWindow(map[string]string{"start": "2024-01-01T13:00:00Z"})),
+		Entry("at the end", newWindow(map[string]string{"end": "2024-01-01T12:00:00Z"})),
+		Entry("with an invalid time", newWindow(map[string]string{"start": "noon"})),
+		Entry("without the ConfigMap", nil),
+	)
+})
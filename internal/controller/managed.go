@@ -0,0 +1,71 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// managedKeys returns the taint keys recorded in the node's managed keys
+// annotation.
+func managedKeys(node *corev1.Node) []string {
+	value := node.GetAnnotations()[nodesv1alpha1.ManagedKeysAnnotation]
+	if value == "" {
+		return nil
+	}
+	var keys []string
+	for _, k := range strings.Split(value, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// mergeManagedKeys returns the managed keys annotation value of the node
+// with the keys of the removed taints added.
+func mergeManagedKeys(node *corev1.Node, removed []*corev1.Taint) string {
+	keys := managedKeys(node)
+	for _, t := range removed {
+		keys = append(keys, t.Key)
+	}
+	slices.Sort(keys)
+	return strings.Join(slices.Compact(keys), ",")
+}
+
+// ownedTaint reports whether the controller may remove the target taint
+// from the node. In only-manage-own mode, only taints whose key is recorded
+// in the node's managed keys annotation may be removed.
+func (r *TaintRemoverReconciler) ownedTaint(node *corev1.Node, target *removeTarget) bool {
+	if !r.OnlyManageOwn {
+		return true
+	}
+	return slices.Contains(managedKeys(node), target.Taint.Key)
+}
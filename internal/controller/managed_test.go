@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("managed keys", func() {
+	var (
+		ctx   context.Context
+		taint corev1.Taint
+		node  *corev1.Node
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		taint = corev1.Taint{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-node",
+				Annotations: map[string]string{"example.com/other": "keep"},
+			},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+	})
+
+	It("should record removed keys on the node", func() {
+		c := newFakeClient(node)
+		reconciler := &TaintRemoverReconciler{Client: c}
+
+		result, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(BeEmpty())
+		Expect(node.Annotations).To(HaveKeyWithValue(nodesv1alpha1.ManagedKeysAnnotation, "foo"))
+		Expect(node.Annotations).To(HaveKeyWithValue("example.com/other", "keep"))
+	})
+
+	Context("When only managing own taints", func() {
+		It("should leave an untracked taint alone", func() {
+			c := newFakeClient(node)
+			reconciler := &TaintRemoverReconciler{Client: c, OnlyManageOwn: true}
+
+			result, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.NodesPatched).To(BeZero())
+
+			Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+			Expect(node.Spec.Taints).To(HaveLen(1))
+		})
+
+		It("should remove a taint once its key is explicitly allowed", func() {
+			node.Annotations[nodesv1alpha1.ManagedKeysAnnotation] = "baz,foo"
+			c := newFakeClient(node)
+			reconciler := &TaintRemoverReconciler{Client: c, OnlyManageOwn: true}
+
+			result, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.NodesPatched).To(Equal(1))
+
+			Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+			Expect(node.Spec.Taints).To(BeEmpty())
+			Expect(node.Annotations).To(HaveKeyWithValue(nodesv1alpha1.ManagedKeysAnnotation, "baz,foo"))
+		})
+	})
+
+	Describe("mergeManagedKeys", func() {
+		It("should add removed keys without duplicates", func() {
+			node.Annotations[nodesv1alpha1.ManagedKeysAnnotation] = "zzz, foo"
+			removed := []*corev1.Taint{{Key: "foo"}, {Key: "aaa"}}
+			Expect(mergeManagedKeys(node, removed)).To(Equal("aaa,foo,zzz"))
+		})
+	})
+})
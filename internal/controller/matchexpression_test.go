@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("MatchExpression", func() {
+	var (
+		ctx      context.Context
+		spot     corev1.Taint
+		gpu      corev1.Taint
+		draining corev1.Taint
+		cordoned corev1.Taint
+		node     *corev1.Node
+		recorder *record.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		spot = corev1.Taint{Key: "example.com/spot", Value: "v2", Effect: corev1.TaintEffectNoSchedule}
+		gpu = corev1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: corev1.TaintEffectNoSchedule}
+		draining = corev1.Taint{Key: "example.com/draining", Effect: corev1.TaintEffectPreferNoSchedule}
+		cordoned = corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node", Labels: map[string]string{"pool": "gpu"}},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{spot, gpu, draining, cordoned}},
+		}
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	reconcileWith := func(expression string) []corev1.Taint {
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{MatchExpression: expression},
+		}
+		c := newFakeClient(node, tr)
+		_, err := (&TaintRemoverReconciler{Client: c, Recorder: recorder}).Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		return node.Spec.Taints
+	}
+
+	It("should remove the taints whose key the expression matches", func() {
+		taints := reconcileWith(`taint.key.startsWith("example.com/")`)
+		Expect(taints).To(Equal([]corev1.Taint{gpu, cordoned}))
+	})
+
+	It("should match on the taint value and effect", func() {
+		taints := reconcileWith(`taint.value.matches("^v[0-9]+$") || taint.effect == "PreferNoSchedule"`)
+		Expect(taints).To(Equal([]corev1.Taint{gpu, cordoned}))
+	})
+
+	It("should match on the node labels", func() {
+		taints := reconcileWith(`node.labels["pool"] == "gpu" && taint.key == "nvidia.com/gpu"`)
+		Expect(taints).To(Equal([]corev1.Taint{spot, draining, cordoned}))
+	})
+
+	It("should not match when the expression fails on a missing label", func() {
+		taints := reconcileWith(`node.labels["team"] == "ml"`)
+		Expect(taints).To(Equal([]corev1.Taint{spot, gpu, draining, cordoned}))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should skip an invalid expression with a Warning event", func() {
+		taints := reconcileWith(`taint.key.startsWith(`)
+		Expect(taints).To(Equal([]corev1.Taint{spot, gpu, draining, cordoned}))
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " InvalidMatchExpression")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should compile the expression once for the remover", func() {
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{MatchExpression: `taint.effect == "NoSchedule"`},
+		}
+		targets, err := newRemoveTargets(tr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(targets).To(HaveLen(1))
+		Expect(targets[0].program).NotTo(BeNil())
+		Expect(targets[0].validate()).To(Succeed())
+		Expect(targets[0].candidates(node, exactKeyMatch)).To(HaveLen(3))
+	})
+})
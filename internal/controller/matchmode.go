@@ -0,0 +1,121 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// matchMode is how a listed taint matches the node taints, as set by the
+// MatchModeAnnotation of its remover.
+type matchMode string
+
+const (
+	// matchModeExact matches the taints with the same key and effect.
+	matchModeExact matchMode = "exact"
+	// matchModePrefix matches the taints with the same effect whose key
+	// starts with the listed key.
+	matchModePrefix matchMode = "prefix"
+	// matchModeKeyOnly matches the taints with the same key, whatever their
+	// effect.
+	matchModeKeyOnly matchMode = "key-only"
+	// matchModeRegex matches the taints with the same effect whose whole key
+	// matches the listed key as a regular expression.
+	matchModeRegex matchMode = "regex"
+)
+
+// parseMatchMode returns the match mode named by the annotation value.
+// An empty value is the exact mode, and so is an invalid one along with an
+// error.
+func parseMatchMode(value string) (matchMode, error) {
+	switch mode := matchMode(value); mode {
+	case "":
+		return matchModeExact, nil
+	case matchModeExact, matchModePrefix, matchModeKeyOnly, matchModeRegex:
+		return mode, nil
+	default:
+		return matchModeExact, fmt.Errorf("unknown match mode %q", value)
+	}
+}
+
+// setMatchMode sets the match mode of a listed taint target. The key of a
+// regex target is compiled once here; an invalid one is reported by validate.
+func (t *removeTarget) setMatchMode(mode matchMode) {
+	t.matchMode = mode
+	if mode == matchModeRegex {
+		t.keyPattern, _ = compileKeyPattern(t.Taint.Key)
+	}
+}
+
+// setEffectOnly makes a listed taint target with an empty key and an effect
+// match any key when its remover allows effect-only taints.
+func (t *removeTarget) setEffectOnly(allowed bool) {
+	t.effectOnly = allowed && t.listed() && t.Taint.Key == "" && t.Taint.Effect != ""
+}
+
+// compileKeyPattern compiles the key of a regex target so that it matches
+// whole keys only.
+func compileKeyPattern(key string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + key + ")$")
+}
+
+// validateMatch checks the taint of a listed taint target for its match mode.
+func (t *removeTarget) validateMatch() error {
+	switch t.matchMode {
+	case matchModePrefix:
+		if t.Taint.Key == "" {
+			return fmt.Errorf("empty taint key prefix")
+		}
+		return nil
+	case matchModeRegex:
+		_, err := compileKeyPattern(t.Taint.Key)
+		return err
+	default:
+		return nil
+	}
+}
+
+// matchesTaint reports whether the listed taint target matches the node
+// taint in its match mode. An effect-only target matches the unprotected
+// node taints with its effect whatever the mode.
+func (t *removeTarget) matchesTaint(nt *corev1.Taint, keyMatch keyMatcher) bool {
+	if t.effectOnly {
+		return nt.Effect == t.Taint.Effect && !isProtectedTaint(nt)
+	}
+	switch t.matchMode {
+	case matchModePrefix:
+		return nt.Effect == t.Taint.Effect && strings.HasPrefix(nt.Key, t.Taint.Key)
+	case matchModeKeyOnly:
+		return keyMatch(nt.Key, t.Taint.Key)
+	case matchModeRegex:
+		return nt.Effect == t.Taint.Effect && t.keyPattern != nil && t.keyPattern.MatchString(nt.Key)
+	default:
+		return nt.Effect == t.Taint.Effect && keyMatch(nt.Key, t.Taint.Key)
+	}
+}
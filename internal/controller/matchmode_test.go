@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("MatchModeAnnotation", func() {
+	var (
+		ctx      context.Context
+		exact    corev1.Taint
+		prefixed corev1.Taint
+		other    corev1.Taint
+		node     *corev1.Node
+		recorder *record.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		exact = corev1.Taint{Key: "example.com/spot", Effect: corev1.TaintEffectNoSchedule}
+		prefixed = corev1.Taint{Key: "example.com/spot-preempted", Effect: corev1.TaintEffectNoSchedule}
+		other = corev1.Taint{Key: "example.com/spot", Effect: corev1.TaintEffectPreferNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{exact, prefixed, other}},
+		}
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	reconcileWith := func(annotations map[string]string, taint corev1.Taint) []corev1.Taint {
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover", Annotations: annotations},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}},
+		}
+		c := newFakeClient(node, tr)
+		_, err := (&TaintRemoverReconciler{Client: c, Recorder: recorder}).Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		return node.Spec.Taints
+	}
+	withMode := func(mode string) map[string]string {
+		return map[string]string{nodesv1alpha1.MatchModeAnnotation: mode}
+	}
+
+	It("should match exactly without the annotation", func() {
+		Expect(reconcileWith(nil, exact)).To(Equal([]corev1.Taint{prefixed, other}))
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeNormal + " TaintsRemoved")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should match exactly in the exact mode", func() {
+		Expect(reconcileWith(withMode("exact"), exact)).To(Equal([]corev1.Taint{prefixed, other}))
+	})
+
+	It("should match exactly with an invalid mode", func() {
+		Expect(reconcileWith(withMode("fuzzy"), exact)).To(Equal([]corev1.Taint{prefixed, other}))
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " InvalidMatchMode")))
+	})
+
+	It("should match the keys starting with the key in the prefix mode", func() {
+		taint := corev1.Taint{Key: "example.com/spot", Effect: corev1.TaintEffectNoSchedule}
+		Expect(reconcileWith(withMode("prefix"), taint)).To(Equal([]corev1.Taint{other}))
+	})
+
+	It("should match any effect in the key-only mode", func() {
+		Expect(reconcileWith(withMode("key-only"), exact)).To(Equal([]corev1.Taint{prefixed}))
+	})
+
+	It("should match the whole key in the regex mode", func() {
+		taint := corev1.Taint{Key: `example\.com/spot-.+`, Effect: corev1.TaintEffectNoSchedule}
+		Expect(reconcileWith(withMode("regex"), taint)).To(Equal([]corev1.Taint{exact, other}))
+	})
+
+	It("should skip an invalid regex", func() {
+		taint := corev1.Taint{Key: "example.com/(spot", Effect: corev1.TaintEffectNoSchedule}
+		Expect(reconcileWith(withMode("regex"), taint)).To(HaveLen(3))
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " InvalidTaint")))
+	})
+})
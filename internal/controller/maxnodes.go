@@ -0,0 +1,136 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// nodeCaps tracks the nodes each remover limited by MaxNodes contributed to
+// since the start of the last sweep, so that the node events following a
+// sweep count toward its limit. A contribution is reserved when a removal is
+// allowed, and kept once the node is patched or released otherwise.
+type nodeCaps struct {
+	mu sync.Mutex
+	// contributed holds the nodes of each remover, mapped to whether they
+	// were patched.
+	contributed map[string]map[string]bool
+}
+
+// reset starts a new sweep window.
+func (c *nodeCaps) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.contributed = nil
+}
+
+// allow reports whether the remover may contribute to the node, reserving
+// the contribution. A remover may keep contributing to the nodes it already
+// contributed to.
+func (c *nodeCaps) allow(remover, node string, maxNodes int32) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.contributed == nil {
+		c.contributed = make(map[string]map[string]bool)
+	}
+	nodes := c.contributed[remover]
+	if nodes == nil {
+		nodes = make(map[string]bool)
+		c.contributed[remover] = nodes
+	}
+	if _, ok := nodes[node]; ok {
+		return true
+	}
+	if len(nodes) >= int(maxNodes) {
+		return false
+	}
+	nodes[node] = false
+	return true
+}
+
+// commit keeps the contributions reserved for the node once it is patched.
+func (c *nodeCaps) commit(node string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, nodes := range c.contributed {
+		if _, ok := nodes[node]; ok {
+			nodes[node] = true
+		}
+	}
+}
+
+// release drops the contributions reserved for the node when it is not
+// patched, so that they do not count toward the limits.
+func (c *nodeCaps) release(node string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, nodes := range c.contributed {
+		if patched, ok := nodes[node]; ok && !patched {
+			delete(nodes, node)
+		}
+	}
+}
+
+// startCapWindow starts the window of a sweep in which the removers limited
+// by MaxNodes contribute to at most that many nodes.
+func (r *TaintRemoverReconciler) startCapWindow() {
+	r.caps.reset()
+	r.capPending.Store(false)
+}
+
+// retryCappedLater requests a sweep after gatedRequeue for the removals kept
+// by a node event once the removers reached their limit, as no sweep may
+// follow otherwise. Only one request is pending at a time.
+func (r *TaintRemoverReconciler) retryCappedLater() {
+	if !r.capRetry.CompareAndSwap(false, true) {
+		return
+	}
+	time.AfterFunc(gatedRequeue, func() {
+		r.capRetry.Store(false)
+		r.TriggerReconcile()
+	})
+}
+
+// maxNodesFilter returns a removal filter that keeps the taints of removers
+// limited by MaxNodes once they have contributed to that many nodes in the
+// sweep window, marking the sweep for a retry. The window spans a sweep and
+// the node events following it.
+func (r *TaintRemoverReconciler) maxNodesFilter(ctx context.Context) removalFilter {
+	logger := log.FromContext(ctx)
+	return func(node *corev1.Node, target *removeTarget) bool {
+		if target.MaxNodes == nil || r.caps.allow(target.remover, node.Name, *target.MaxNodes) {
+			return true
+		}
+		logger.V(1).Info("Keeping taint, remover reached its node limit", "node", node.Name,
+			"taint", target.Taint.ToString(), "remover", target.remover, "maxNodes", *target.MaxNodes)
+		r.capPending.Store(true)
+		return false
+	}
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("max nodes", func() {
+	var (
+		taint corev1.Taint
+		other corev1.Taint
+		nodes []*corev1.Node
+		objs  []client.Object
+	)
+
+	BeforeEach(func() {
+		taint = corev1.Taint{Key: "taint", Effect: corev1.TaintEffectNoSchedule}
+		other = corev1.Taint{Key: "other", Effect: corev1.TaintEffectNoSchedule}
+		nodes, objs = nil, nil
+		for i := 0; i < 3; i++ {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint, other}},
+			}
+			nodes = append(nodes, node)
+			objs = append(objs, node)
+		}
+	})
+
+	countTainted := func(c client.Client, t corev1.Taint) int {
+		count := 0
+		for _, n := range nodes {
+			node := &corev1.Node{}
+			Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(n), node)).To(Succeed())
+			for _, nt := range node.Spec.Taints {
+				if nt.MatchTaint(&t) {
+					count++
+				}
+			}
+		}
+		return count
+	}
+
+	It("should patch at most MaxNodes nodes for the remover and requeue", func() {
+		targets := []*removeTarget{
+			{Taint: taint, MaxNodes: int32Ptr(2), remover: "capped"},
+			{Taint: other, remover: "unlimited"},
+		}
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(objs...)}
+		result, err := reconciler.removeTaints(context.TODO(), nodes, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(3))
+		Expect(result.TaintsRemoved).To(Equal(5))
+		Expect(countTainted(reconciler.Client, taint)).To(Equal(1))
+		Expect(countTainted(reconciler.Client, other)).To(BeZero())
+		Expect(reconciler.nextRequeue()).To(Equal(gatedRequeue))
+	})
+
+	It("should remove several taints of the remover from the same node", func() {
+		targets := []*removeTarget{
+			{Taint: taint, MaxNodes: int32Ptr(1), remover: "capped"},
+			{Taint: other, MaxNodes: int32Ptr(1), remover: "capped"},
+		}
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(objs...)}
+		result, err := reconciler.removeTaints(context.TODO(), nodes, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+		Expect(result.TaintsRemoved).To(Equal(2))
+	})
+
+	It("should not requeue when the remover is within its limit", func() {
+		targets := []*removeTarget{{Taint: taint, MaxNodes: int32Ptr(3), remover: "capped"}}
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(objs...)}
+		result, err := reconciler.removeTaints(context.TODO(), nodes, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(3))
+		Expect(reconciler.nextRequeue()).To(BeZero())
+	})
+
+	Context("When nodes arrive through node events", func() {
+		var remover *nodesv1alpha1.TaintRemover
+
+		BeforeEach(func() {
+			remover = &nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "capped"},
+				Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}, MaxNodes: int32Ptr(2)},
+			}
+		})
+
+		It("should count the node events toward MaxNodes", func() {
+			reconciler := &TaintRemoverReconciler{Client: newFakeClient(append(objs, remover)...)}
+			for _, n := range nodes {
+				Expect(reconciler.applyTaintRemoveOnNode(context.TODO(), n)).To(Succeed())
+			}
+			Expect(countTainted(reconciler.Client, taint)).To(Equal(1))
+			Expect(reconciler.capPending.Load()).To(BeTrue())
+			Expect(reconciler.capRetry.Load()).To(BeTrue())
+		})
+
+		It("should count the node events following a sweep toward its limit", func() {
+			reconciler := &TaintRemoverReconciler{Client: newFakeClient(append(objs, remover)...)}
+			reconciler.startCapWindow()
+			_, err := reconciler.removeTaints(context.TODO(), nodes[:2], []*removeTarget{
+				{Taint: taint, MaxNodes: int32Ptr(2), remover: "capped"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reconciler.applyTaintRemoveOnNode(context.TODO(), nodes[2])).To(Succeed())
+			Expect(countTainted(reconciler.Client, taint)).To(Equal(1))
+
+			reconciler.startCapWindow()
+			Expect(reconciler.applyTaintRemoveOnNode(context.TODO(), nodes[2])).To(Succeed())
+			Expect(countTainted(reconciler.Client, taint)).To(BeZero())
+		})
+	})
+
+	It("should not count a failed patch toward MaxNodes", func() {
+		c := &erroringClient{
+			Client: newFakeClient(objs...),
+			patchErr: func(obj client.Object) error {
+				if obj.GetName() == "node-0" {
+					return goerrors.New("patch failed")
+				}
+				return nil
+			},
+		}
+		targets := []*removeTarget{{Taint: taint, MaxNodes: int32Ptr(2), remover: "capped"}}
+		reconciler := &TaintRemoverReconciler{Client: c}
+		reconciler.startCapWindow()
+		result, err := reconciler.removeTaints(context.TODO(), nodes, targets)
+		Expect(err).To(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+
+		result, err = reconciler.removeTaints(context.TODO(), nodes[2:], targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+		Expect(countTainted(reconciler.Client, taint)).To(Equal(1))
+	})
+
+	It("should keep the targets of different capped removers apart", func() {
+		a := removeTarget{Taint: taint, MaxNodes: int32Ptr(1), remover: "a"}
+		b := removeTarget{Taint: taint, MaxNodes: int32Ptr(1), remover: "b"}
+		Expect(a.sameRestrictions(&b)).To(BeFalse())
+		b.remover = "a"
+		Expect(a.sameRestrictions(&b)).To(BeTrue())
+	})
+})
+
+func int32Ptr(n int32) *int32 {
+	return &n
+}
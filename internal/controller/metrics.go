@@ -0,0 +1,170 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// forbiddenErrors counts the API requests rejected due to missing RBAC permissions.
+	forbiddenErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taintremover_forbidden_errors_total",
+			Help: "Number of API requests forbidden due to missing RBAC permissions",
+		},
+		[]string{"verb", "resource"},
+	)
+	// taintsRemovedByRole counts the taints removed by the role of the node.
+	taintsRemovedByRole = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taintremover_taints_removed_by_role_total",
+			Help: "Number of taints removed by node role",
+		},
+		[]string{"role"},
+	)
+	// taintsRemovedByZone counts the taints removed by the zone of the node.
+	taintsRemovedByZone = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taintremover_taints_removed_by_zone_total",
+			Help: "Number of taints removed by node zone",
+		},
+		[]string{"zone"},
+	)
+	// oversizedPatches counts the node patches skipped for exceeding
+	// MaxPatchBytes.
+	oversizedPatches = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "taintremover_oversized_patches_total",
+			Help: "Number of node patches skipped for exceeding the maximum patch size",
+		},
+	)
+	// workqueueDepth is the number of requests pending in the workqueue as
+	// last seen by the node event handler.
+	workqueueDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "taintremover_workqueue_depth",
+			Help: "Number of requests pending in the workqueue",
+		},
+	)
+	// lastRemoval tracks the time of the last taint removal.
+	lastRemoval = newRemovalClock(time.Now)
+	// secondsSinceLastRemoval is the time elapsed since the last taint
+	// removal, computed from lastRemoval on collection.
+	secondsSinceLastRemoval = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "taintremover_seconds_since_last_removal",
+			Help: "Seconds elapsed since a taint was last removed, or since the controller started if none was",
+		},
+		lastRemoval.secondsSince,
+	)
+)
+
+// nodeRoleLabelPrefix is the prefix of the node labels naming the node roles.
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// noRole is the role of a node without role labels.
+const noRole = "none"
+
+// unknownZone is the zone of a node without a zone label.
+const unknownZone = "unknown"
+
+func init() {
+	metrics.Registry.MustRegister(forbiddenErrors, taintsRemovedByRole, taintsRemovedByZone, oversizedPatches,
+		workqueueDepth, secondsSinceLastRemoval)
+}
+
+// nodeRoles returns the sorted roles of the node from its
+// node-role.kubernetes.io/* labels.
+func nodeRoles(node *corev1.Node) []string {
+	var roles []string
+	for k := range node.Labels {
+		if role, ok := strings.CutPrefix(k, nodeRoleLabelPrefix); ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return []string{noRole}
+	}
+	slices.Sort(roles)
+	return roles
+}
+
+// countRemovedByRole counts the taints removed from the node for each of
+// its roles.
+func countRemovedByRole(node *corev1.Node, removed int) {
+	if removed <= 0 {
+		return
+	}
+	for _, role := range nodeRoles(node) {
+		taintsRemovedByRole.WithLabelValues(role).Add(float64(removed))
+	}
+}
+
+// nodeZone returns the zone of the node from its topology.kubernetes.io/zone
+// label.
+func nodeZone(node *corev1.Node) string {
+	if zone := node.Labels[corev1.LabelTopologyZone]; zone != "" {
+		return zone
+	}
+	return unknownZone
+}
+
+// countRemovedByZone counts the taints removed from the node for its zone.
+func countRemovedByZone(node *corev1.Node, removed int) {
+	if removed <= 0 {
+		return
+	}
+	taintsRemovedByZone.WithLabelValues(nodeZone(node)).Add(float64(removed))
+}
+
+// observeQueueDepth records and logs the number of requests pending in the
+// workqueue.
+func observeQueueDepth(ctx context.Context, q workqueue.RateLimitingInterface) {
+	depth := q.Len()
+	workqueueDepth.Set(float64(depth))
+	log.FromContext(ctx).V(1).Info("Pending requests", "depth", depth)
+}
+
+// checkForbidden counts and logs the error if the API server rejected the
+// request due to missing RBAC permissions.
+func checkForbidden(ctx context.Context, err error, verb, resource string) {
+	if !errors.IsForbidden(err) {
+		return
+	}
+	forbiddenErrors.WithLabelValues(verb, resource).Inc()
+	log.FromContext(ctx).Error(err, "Request forbidden, check that the controller's ClusterRole grants the permission",
+		"verb", verb, "resource", resource)
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("forbidden errors", func() {
+	It("should count a forbidden list", func() {
+		counter := forbiddenErrors.WithLabelValues("list", "taintremovers")
+		before := testutil.ToFloat64(counter)
+
+		_, err := getAllRemoveTaints(context.TODO(), newForbiddenListClient(), nil, targetSource{})
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 1))
+	})
+
+	It("should count a forbidden patch", func() {
+		counter := forbiddenErrors.WithLabelValues("patch", "nodes")
+		before := testutil.ToFloat64(counter)
+
+		taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		reconciler := &TaintRemoverReconciler{Client: newForbiddenPatchClient(node)}
+		_, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 1))
+	})
+
+	It("should not count other errors", func() {
+		counter := forbiddenErrors.WithLabelValues("list", "taintremovers")
+		before := testutil.ToFloat64(counter)
+
+		checkForbidden(context.TODO(), apierrors.NewNotFound(nodesv1alpha1.GroupVersion.WithResource("taintremovers").GroupResource(), ""), "list", "taintremovers")
+		Expect(testutil.ToFloat64(counter)).To(Equal(before))
+	})
+})
+
+var _ = Describe("taints removed by role", func() {
+	It("should extract the roles from the node labels", func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+			"node-role.kubernetes.io/worker": "",
+			"node-role.kubernetes.io/infra":  "true",
+			"kubernetes.io/hostname":         "test-node",
+		}}}
+		Expect(nodeRoles(node)).To(Equal([]string{"infra", "worker"}))
+		Expect(nodeRoles(&corev1.Node{})).To(Equal([]string{noRole}))
+	})
+
+	It("should count the removed taints by the node role", func() {
+		worker := taintsRemovedByRole.WithLabelValues("worker")
+		infra := taintsRemovedByRole.WithLabelValues("infra")
+		none := taintsRemovedByRole.WithLabelValues(noRole)
+		beforeWorker, beforeInfra, beforeNone := testutil.ToFloat64(worker), testutil.ToFloat64(infra), testutil.ToFloat64(none)
+
+		foo := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		bar := corev1.Taint{Key: "bar", Effect: corev1.TaintEffectNoSchedule}
+		nodes := []*corev1.Node{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker-node",
+					Labels: map[string]string{"node-role.kubernetes.io/worker": ""}},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{foo, bar}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "infra-node",
+					Labels: map[string]string{"node-role.kubernetes.io/infra": ""}},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{foo}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "plain-node"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{bar}},
+			},
+		}
+		reconciler := newFakeReconciler(nodes[0], nodes[1], nodes[2])
+		_, err := reconciler.removeTaints(context.TODO(), nodes, []*removeTarget{{Taint: foo}, {Taint: bar}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(testutil.ToFloat64(worker)).To(Equal(beforeWorker + 2))
+		Expect(testutil.ToFloat64(infra)).To(Equal(beforeInfra + 1))
+		Expect(testutil.ToFloat64(none)).To(Equal(beforeNone + 1))
+	})
+})
+
+var _ = Describe("taints removed by zone", func() {
+	It("should extract the zone from the node labels", func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+			corev1.LabelTopologyZone: "zone-a",
+		}}}
+		Expect(nodeZone(node)).To(Equal("zone-a"))
+		Expect(nodeZone(&corev1.Node{})).To(Equal(unknownZone))
+	})
+
+	It("should count the removed taints by the node zone", func() {
+		zoneA := taintsRemovedByZone.WithLabelValues("zone-a")
+		zoneB := taintsRemovedByZone.WithLabelValues("zone-b")
+		unknown := taintsRemovedByZone.WithLabelValues(unknownZone)
+		beforeA, beforeB, beforeUnknown := testutil.ToFloat64(zoneA), testutil.ToFloat64(zoneB), testutil.ToFloat64(unknown)
+
+		foo := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		bar := corev1.Taint{Key: "bar", Effect: corev1.TaintEffectNoSchedule}
+		nodes := []*corev1.Node{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "zone-a-node",
+					Labels: map[string]string{corev1.LabelTopologyZone: "zone-a"}},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{foo, bar}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "zone-b-node",
+					Labels: map[string]string{corev1.LabelTopologyZone: "zone-b"}},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{foo}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "zoneless-node"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{bar}},
+			},
+		}
+		reconciler := newFakeReconciler(nodes[0], nodes[1], nodes[2])
+		_, err := reconciler.removeTaints(context.TODO(), nodes, []*removeTarget{{Taint: foo}, {Taint: bar}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(testutil.ToFloat64(zoneA)).To(Equal(beforeA + 2))
+		Expect(testutil.ToFloat64(zoneB)).To(Equal(beforeB + 1))
+		Expect(testutil.ToFloat64(unknown)).To(Equal(beforeUnknown + 1))
+	})
+})
+
+var _ = Describe("workqueue depth", func() {
+	It("should reflect the pending requests", func() {
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+		for _, name := range []string{"a", "b", "c"} {
+			q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		}
+
+		nh := &nodeHandler{r: newFakeReconciler()}
+		nh.Generic(context.TODO(), event.GenericEvent{}, q)
+		Expect(testutil.ToFloat64(workqueueDepth)).To(Equal(3.0))
+
+		item, _ := q.Get()
+		q.Done(item)
+		q.Forget(item)
+		nh.Delete(context.TODO(), event.DeleteEvent{}, q)
+		Expect(testutil.ToFloat64(workqueueDepth)).To(Equal(2.0))
+	})
+})
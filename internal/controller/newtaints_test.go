@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// scanNewTaintsForNode is the taint by taint deletion makeNewTaintsForNode
+// replaces, kept as the reference of its behavior.
+func scanNewTaintsForNode(target *corev1.Node, taints []*removeTarget, keyMatch keyMatcher) ([]corev1.Taint, bool) {
+	nodeTaints := target.Spec.Taints
+	deleted := false
+	for _, taint := range taints {
+		if !taint.selects(target) {
+			continue
+		}
+		for _, candidate := range taint.candidates(target, keyMatch) {
+			if candidate.effectExcluded() {
+				continue
+			}
+			var taintDeleted bool
+			nodeTaints, taintDeleted = tutil.DeleteTaint(nodeTaints, &candidate.Taint)
+			deleted = deleted || taintDeleted
+		}
+	}
+	return nodeTaints, deleted
+}
+
+// manyTaintedNode returns a node with n taints and the targets listing every
+// other one of them, some of them twice.
+func manyTaintedNode(n int) (*corev1.Node, []*removeTarget) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	var targets []*removeTarget
+	for i := 0; i < n; i++ {
+		taint := corev1.Taint{Key: fmt.Sprintf("example.com/taint-%d", i), Effect: corev1.TaintEffectNoSchedule}
+		node.Spec.Taints = append(node.Spec.Taints, taint)
+		if i%2 == 0 {
+			targets = append(targets, &removeTarget{Taint: taint})
+		}
+		if i%6 == 0 {
+			targets = append(targets, &removeTarget{Taint: taint, remover: "other"})
+		}
+	}
+	return node, targets
+}
+
+var _ = Describe("makeNewTaintsForNode", func() {
+	It("should keep the same taints as deleting them one by one", func() {
+		node, targets := manyTaintedNode(60)
+		want, wantDeleted := scanNewTaintsForNode(node, targets, exactKeyMatch)
+		got, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch)
+		Expect(deleted).To(Equal(wantDeleted))
+		Expect(got).To(Equal(want))
+		Expect(got).To(HaveLen(30))
+	})
+
+	It("should offer a taint listed twice to the filters once", func() {
+		node, targets := manyTaintedNode(6)
+		offered := 0
+		_, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch,
+			func(_ *corev1.Node, target *removeTarget) bool {
+				if target.Taint.Key == "example.com/taint-0" {
+					offered++
+				}
+				return true
+			})
+		Expect(deleted).To(BeTrue())
+		Expect(offered).To(Equal(1))
+	})
+})
+
+func BenchmarkScanNewTaintsForNode(b *testing.B) {
+	node, targets := manyTaintedNode(200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scanNewTaintsForNode(node, targets, exactKeyMatch)
+	}
+}
+
+func BenchmarkMakeNewTaintsForNode(b *testing.B) {
+	node, targets := manyTaintedNode(200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		makeNewTaintsForNode(node, targets, exactKeyMatch)
+	}
+}
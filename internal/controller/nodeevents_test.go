@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/reference"
+)
+
+// capturingRecorder is an event recorder keeping the involved objects of
+// the events.
+type capturingRecorder struct {
+	objects []runtime.Object
+}
+
+func (c *capturingRecorder) Event(object runtime.Object, _, _, _ string) {
+	c.objects = append(c.objects, object)
+}
+
+func (c *capturingRecorder) Eventf(object runtime.Object, _, _, _ string, _ ...interface{}) {
+	c.objects = append(c.objects, object)
+}
+
+func (c *capturingRecorder) AnnotatedEventf(object runtime.Object, _ map[string]string, _, _, _ string,
+	_ ...interface{}) {
+	c.objects = append(c.objects, object)
+}
+
+var _ = Describe("node events", func() {
+	It("should involve the node by name and UID", func() {
+		taint := corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node", UID: types.UID("0a1b2c3d")},
+			Spec:       corev1.NodeSpec{Unschedulable: true, Taints: []corev1.Taint{taint}},
+		}
+		recorder := &capturingRecorder{}
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(node), Recorder: recorder}
+		_, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.objects).To(HaveLen(1))
+
+		// An empty scheme shows that the reference does not depend on the
+		// scheme of the recorder.
+		ref, err := reference.GetReference(runtime.NewScheme(), recorder.objects[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*ref).To(Equal(corev1.ObjectReference{
+			Kind:       "Node",
+			APIVersion: "v1",
+			Name:       "test-node",
+			UID:        types.UID("0a1b2c3d"),
+		}))
+	})
+})
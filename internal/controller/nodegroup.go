@@ -0,0 +1,47 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// defaultNodeGroupAnnotationKey is the node annotation holding the node
+// group when NodeGroupAnnotationKey is empty.
+const defaultNodeGroupAnnotationKey = "karpenter.sh/nodepool"
+
+// nodeGroupKey returns the node annotation holding the node group.
+func (r *TaintRemoverReconciler) nodeGroupKey() string {
+	if r.NodeGroupAnnotationKey == "" {
+		return defaultNodeGroupAnnotationKey
+	}
+	return r.NodeGroupAnnotationKey
+}
+
+// inNodeGroup reports whether the node belongs to the node group of the
+// target. Every node belongs to it when the target has no node group.
+func (r *TaintRemoverReconciler) inNodeGroup(node *corev1.Node, target *removeTarget) bool {
+	return target.NodeGroup == "" || node.Annotations[r.nodeGroupKey()] == target.NodeGroup
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("NodeGroup", func() {
+	var (
+		taint   corev1.Taint
+		spot    *corev1.Node
+		general *corev1.Node
+		nodes   []*corev1.Node
+	)
+
+	newNode := func(name string, annotations map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+	}
+
+	BeforeEach(func() {
+		taint = corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		spot = newNode("spot", map[string]string{
+			"karpenter.sh/nodepool":       "spot",
+			"eks.amazonaws.com/nodegroup": "general",
+		})
+		general = newNode("general", map[string]string{"karpenter.sh/nodepool": "general"})
+		nodes = []*corev1.Node{spot, general}
+	})
+
+	removeWith := func(reconciler *TaintRemoverReconciler, target *removeTarget) {
+		reconciler.Client = newFakeClient(spot, general)
+		_, err := reconciler.removeTaints(context.TODO(), nodes, []*removeTarget{target})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(spot), spot)).To(Succeed())
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(general), general)).To(Succeed())
+	}
+
+	It("should remove the taints only from the nodes of the node group", func() {
+		removeWith(&TaintRemoverReconciler{}, &removeTarget{Taint: taint, NodeGroup: "spot"})
+		Expect(spot.Spec.Taints).To(BeEmpty())
+		Expect(general.Spec.Taints).To(HaveLen(1))
+	})
+
+	It("should match the configured annotation key", func() {
+		removeWith(&TaintRemoverReconciler{NodeGroupAnnotationKey: "eks.amazonaws.com/nodegroup"},
+			&removeTarget{Taint: taint, NodeGroup: "general"})
+		Expect(spot.Spec.Taints).To(BeEmpty())
+		Expect(general.Spec.Taints).To(HaveLen(1))
+	})
+
+	It("should remove the taints from all node groups without a node group", func() {
+		removeWith(&TaintRemoverReconciler{}, &removeTarget{Taint: taint})
+		Expect(spot.Spec.Taints).To(BeEmpty())
+		Expect(general.Spec.Taints).To(BeEmpty())
+	})
+})
@@ -0,0 +1,129 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeLabelField is the field index of the node labels, each indexed as
+// key=value.
+const nodeLabelField = "metadata.labels"
+
+// nodeLabelValues returns the indexed values of the node labels.
+func nodeLabelValues(obj client.Object) []string {
+	values := make([]string, 0, len(obj.GetLabels()))
+	for k, v := range obj.GetLabels() {
+		values = append(values, k+"="+v)
+	}
+	return values
+}
+
+// indexNodeLabels registers the node label index, so that the nodes selected
+// by a NodeSelector are looked up without listing every node. Nothing is
+// registered without an indexer.
+func (r *TaintRemoverReconciler) indexNodeLabels(ctx context.Context, indexer client.FieldIndexer) error {
+	if indexer == nil {
+		return nil
+	}
+	if err := indexer.IndexField(ctx, &corev1.Node{}, nodeLabelField, nodeLabelValues); err != nil {
+		return err
+	}
+	r.nodeLabelsIndexed = true
+	return nil
+}
+
+// indexedLabel returns the key=value of an equality requirement of the
+// selector, which can be looked up in the node label index.
+func indexedLabel(selector labels.Selector) (string, bool) {
+	reqs, _ := selector.Requirements()
+	for _, req := range reqs {
+		values := req.Values().List()
+		switch req.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			if len(values) == 1 {
+				return req.Key() + "=" + values[0], true
+			}
+		}
+	}
+	return "", false
+}
+
+// listSelectedNodes lists the nodes selected by the selector. The node label
+// index narrows the list when registered, and the label selector is passed
+// to the client otherwise.
+func (r *TaintRemoverReconciler) listSelectedNodes(ctx context.Context, selector labels.Selector) ([]corev1.Node, error) {
+	list := &corev1.NodeList{}
+	opts := []client.ListOption{client.MatchingLabelsSelector{Selector: selector}}
+	if label, ok := indexedLabel(selector); ok && r.nodeLabelsIndexed {
+		opts = []client.ListOption{client.MatchingFields{nodeLabelField: label}}
+	}
+	if err := r.List(ctx, list, opts...); err != nil {
+		checkForbidden(ctx, err, "list", "nodes")
+		return nil, err
+	}
+	return slices.DeleteFunc(list.Items, func(n corev1.Node) bool {
+		return !selector.Matches(labels.Set(n.Labels))
+	}), nil
+}
+
+// getTargetNodes returns the tainted nodes the targets may remove taints
+// from. Only the selected nodes are listed when every target has a node
+// selector, and every node otherwise.
+func (r *TaintRemoverReconciler) getTargetNodes(ctx context.Context, taints []*removeTarget) ([]*corev1.Node, error) {
+	selectors := make(map[string]labels.Selector)
+	for _, t := range taints {
+		if t.selector == nil || t.selector.Empty() {
+			return getTaintedNodes(ctx, r.Client)
+		}
+		selectors[t.selector.String()] = t.selector
+	}
+
+	seen := make(map[string]bool)
+	var nodes []*corev1.Node
+	for _, selector := range selectors {
+		selected, err := r.listSelectedNodes(ctx, selector)
+		if err != nil {
+			return nil, err
+		}
+		for i := range selected {
+			n := &selected[i]
+			if len(n.Spec.Taints) < 1 || seen[n.Name] {
+				continue
+			}
+			seen[n.Name] = true
+			nodes = append(nodes, n)
+		}
+	}
+	slices.SortFunc(nodes, func(a, b *corev1.Node) int { return strings.Compare(a.Name, b.Name) })
+	return nodes, nil
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// recordingIndexer is a field indexer recording the indexed fields.
+type recordingIndexer struct {
+	fields []string
+}
+
+func (f *recordingIndexer) IndexField(_ context.Context, _ client.Object, field string, _ client.IndexerFunc) error {
+	f.fields = append(f.fields, field)
+	return nil
+}
+
+// fieldListClient is a client recording whether a List used a field selector.
+type fieldListClient struct {
+	client.Client
+	byField bool
+}
+
+func (c *fieldListClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	o := &client.ListOptions{}
+	o.ApplyOptions(opts)
+	c.byField = c.byField || o.FieldSelector != nil
+	return c.Client.List(ctx, list, opts...)
+}
+
+var _ = Describe("Node label index", func() {
+	var (
+		ctx   context.Context
+		taint corev1.Taint
+		objs  []client.Object
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		taint = corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		newNode := func(name, pool string, taints ...corev1.Taint) *corev1.Node {
+			return &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+				Spec:       corev1.NodeSpec{Taints: taints},
+			}
+		}
+		objs = []client.Object{
+			newNode("gpu-1", "gpu", taint),
+			newNode("gpu-2", "gpu"),
+			newNode("spot-1", "spot", taint),
+			newNode("default-1", "default", taint),
+		}
+	})
+
+	names := func(nodes []*corev1.Node) []string {
+		var result []string
+		for _, n := range nodes {
+			result = append(result, n.Name)
+		}
+		return result
+	}
+
+	selectorFor := func(s string) labels.Selector {
+		selector, err := labels.Parse(s)
+		Expect(err).NotTo(HaveOccurred())
+		return selector
+	}
+
+	It("should register the index", func() {
+		indexer := &recordingIndexer{}
+		r := &TaintRemoverReconciler{}
+		Expect(r.indexNodeLabels(ctx, indexer)).To(Succeed())
+		Expect(indexer.fields).To(ConsistOf(nodeLabelField))
+		Expect(r.nodeLabelsIndexed).To(BeTrue())
+	})
+
+	It("should not register without an indexer", func() {
+		r := &TaintRemoverReconciler{}
+		Expect(r.indexNodeLabels(ctx, nil)).To(Succeed())
+		Expect(r.nodeLabelsIndexed).To(BeFalse())
+	})
+
+	DescribeTable("listing the selected nodes",
+		func(indexed bool, selector string, byField bool, expected ...string) {
+			c := &fieldListClient{Client: newFakeClient(objs...)}
+			r := &TaintRemoverReconciler{Client: c, nodeLabelsIndexed: indexed}
+			nodes, err := r.listSelectedNodes(ctx, selectorFor(selector))
+			Expect(err).NotTo(HaveOccurred())
+			var got []string
+			for _, n := range nodes {
+				got = append(got, n.Name)
+			}
+			Expect(got).To(ConsistOf(expected))
+			Expect(c.byField).To(Equal(byField))
+		},
+		Entry("equality through the index", true, "pool=gpu", true, "gpu-1", "gpu-2"),
+		Entry("single value set through the index", true, "pool in (spot)", true, "spot-1"),
+		Entry("index narrowed by the other requirements", true, "pool=gpu,pool!=spot", true, "gpu-1", "gpu-2"),
+		Entry("multiple value set by label selector", true, "pool in (gpu,spot)", false, "gpu-1", "gpu-2", "spot-1"),
+		Entry("equality by label selector without the index", false, "pool=gpu", false, "gpu-1", "gpu-2"),
+	)
+
+	It("should list the tainted nodes selected by any target", func() {
+		r := &TaintRemoverReconciler{Client: newFakeClient(objs...), nodeLabelsIndexed: true}
+		nodes, err := r.getTargetNodes(ctx, []*removeTarget{
+			{Taint: taint, selector: selectorFor("pool=gpu")},
+			{Taint: taint, selector: selectorFor("pool=spot")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(nodes)).To(Equal([]string{"gpu-1", "spot-1"}))
+	})
+
+	It("should list every tainted node when a target has no selector", func() {
+		r := &TaintRemoverReconciler{Client: newFakeClient(objs...), nodeLabelsIndexed: true}
+		nodes, err := r.getTargetNodes(ctx, []*removeTarget{
+			{Taint: taint, selector: selectorFor("pool=gpu")},
+			{Taint: taint},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(nodes)).To(ConsistOf("gpu-1", "spot-1", "default-1"))
+	})
+})
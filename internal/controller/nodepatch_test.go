@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("node patch", func() {
+	var (
+		ctx     context.Context
+		removed corev1.Taint
+		kept    corev1.Taint
+		node    *corev1.Node
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		removed = corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		kept = corev1.Taint{Key: "example.com/kept", Effect: corev1.TaintEffectNoExecute}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-node",
+				Labels:      map[string]string{"example.com/label": "keep"},
+				Annotations: map[string]string{"example.com/other": "keep"},
+			},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{removed, kept}},
+		}
+	})
+
+	It("should only touch the taint list and the stamped annotations", func() {
+		c := &recordingPatchClient{Client: newFakeClient(node)}
+		reconciler := &TaintRemoverReconciler{Client: c}
+		_, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, []*removeTarget{{Taint: removed}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.types).To(Equal([]types.PatchType{types.StrategicMergePatchType}))
+		var patch struct {
+			Metadata map[string]map[string]string `json:"metadata"`
+			Spec     map[string]json.RawMessage   `json:"spec"`
+		}
+		Expect(json.Unmarshal(c.data[0], &patch)).To(Succeed())
+		Expect(patch.Metadata).To(HaveLen(1))
+		Expect(patch.Metadata).To(HaveKey("annotations"))
+		Expect(patch.Metadata["annotations"]).To(HaveLen(3))
+		Expect(patch.Metadata["annotations"]).To(HaveKey(nodesv1alpha1.ManagedKeysAnnotation))
+		Expect(patch.Metadata["annotations"]).To(HaveKey(nodesv1alpha1.LastPatchAnnotation))
+		Expect(patch.Metadata["annotations"]).To(HaveKey(nodesv1alpha1.HistoryAnnotation))
+		Expect(patch.Spec).To(HaveLen(1))
+		Expect(patch.Spec).To(HaveKey("taints"))
+	})
+
+	It("should keep the annotations added since the node was read", func() {
+		c := newFakeClient(node)
+		current := node.DeepCopy()
+		current.Annotations["example.com/added"] = "keep"
+		Expect(c.Update(ctx, current)).To(Succeed())
+
+		reconciler := &TaintRemoverReconciler{Client: c}
+		result, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, []*removeTarget{{Taint: removed}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+
+		found := &corev1.Node{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(node), found)).To(Succeed())
+		Expect(found.Spec.Taints).To(Equal([]corev1.Taint{kept}))
+		Expect(found.Labels).To(HaveKeyWithValue("example.com/label", "keep"))
+		Expect(found.Annotations).To(HaveKeyWithValue("example.com/other", "keep"))
+		Expect(found.Annotations).To(HaveKeyWithValue("example.com/added", "keep"))
+		Expect(found.Annotations).To(HaveKeyWithValue(nodesv1alpha1.ManagedKeysAnnotation, "foo"))
+		Expect(found.Annotations).To(HaveKey(nodesv1alpha1.LastPatchAnnotation))
+		Expect(found.Annotations).To(HaveKey(nodesv1alpha1.HistoryAnnotation))
+	})
+})
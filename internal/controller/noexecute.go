@@ -0,0 +1,66 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// noExecuteRemovals holds the NoExecute taint removals of a sweep by the
+// name of the remover that requested them.
+type noExecuteRemovals map[string][]nodesv1alpha1.NoExecuteRemoval
+
+// noExecuteFilter returns a removal filter that records the NoExecute taint
+// removals into removals. They are allowed only when ConfirmNoExecute is set.
+// The taints of ObserveOnly targets are left to observeFilter, as they are
+// never removed.
+func (r *TaintRemoverReconciler) noExecuteFilter(removals noExecuteRemovals) removalFilter {
+	return func(node *corev1.Node, target *removeTarget) bool {
+		if target.Taint.Effect != corev1.TaintEffectNoExecute || target.ObserveOnly {
+			return true
+		}
+		removals[target.remover] = append(removals[target.remover], nodesv1alpha1.NoExecuteRemoval{
+			Node:  node.Name,
+			Taint: target.Taint,
+		})
+		return r.ConfirmNoExecute
+	}
+}
+
+// noExecuteEntries returns the NoExecute removals of the remover with the
+// removals found on the processed nodes. Entries for the other nodes are
+// kept.
+func noExecuteEntries(remover *nodesv1alpha1.TaintRemover, processed map[string]bool,
+	removals noExecuteRemovals) []nodesv1alpha1.NoExecuteRemoval {
+	var entries []nodesv1alpha1.NoExecuteRemoval
+	for _, e := range remover.Status.NoExecuteRemovals {
+		if !processed[e.Node] {
+			entries = append(entries, e)
+		}
+	}
+	return append(entries, removals[remover.Name]...)
+}
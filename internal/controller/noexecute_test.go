@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("NoExecute confirmation", func() {
+	var (
+		ctx       context.Context
+		noExecute corev1.Taint
+		noSched   corev1.Taint
+		node      *corev1.Node
+		tr        *nodesv1alpha1.TaintRemover
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		noExecute = corev1.Taint{Key: "example.com/evict", Effect: corev1.TaintEffectNoExecute}
+		noSched = corev1.Taint{Key: "example.com/hold", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{noExecute, noSched}},
+		}
+		tr = &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{noExecute, noSched}},
+		}
+	})
+
+	reconcileWith := func(confirm bool) client.Client {
+		reconciler := newFakeReconciler(node, tr)
+		reconciler.ConfirmNoExecute = confirm
+		c := reconciler.Client
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(c.Get(ctx, types.NamespacedName{Name: tr.Name}, tr)).To(Succeed())
+		return c
+	}
+
+	It("should report and skip NoExecute removals when unconfirmed", func() {
+		reconcileWith(false)
+		Expect(node.Spec.Taints).To(ConsistOf(noExecute))
+		Expect(tr.Status.NoExecuteRemovals).To(ConsistOf(
+			nodesv1alpha1.NoExecuteRemoval{Node: node.Name, Taint: noExecute}))
+	})
+
+	It("should report and perform NoExecute removals when confirmed", func() {
+		reconcileWith(true)
+		Expect(node.Spec.Taints).To(BeEmpty())
+		Expect(tr.Status.NoExecuteRemovals).To(ConsistOf(
+			nodesv1alpha1.NoExecuteRemoval{Node: node.Name, Taint: noExecute}))
+	})
+
+	It("should only preview the NoExecute taints of ObserveOnly removers", func() {
+		tr.Spec.ObserveOnly = true
+		reconcileWith(false)
+		Expect(node.Spec.Taints).To(ConsistOf(noExecute, noSched))
+		Expect(tr.Status.NoExecuteRemovals).To(BeEmpty())
+		Expect(tr.Status.PreviewDiffs).NotTo(BeEmpty())
+	})
+
+	It("should drop reported removals that are no longer pending", func() {
+		node.Spec.Taints = []corev1.Taint{noSched}
+		tr.Status.NoExecuteRemovals = []nodesv1alpha1.NoExecuteRemoval{
+			{Node: node.Name, Taint: noExecute},
+			{Node: "other-node", Taint: noExecute},
+		}
+		reconcileWith(false)
+		Expect(tr.Status.NoExecuteRemovals).To(ConsistOf(
+			nodesv1alpha1.NoExecuteRemoval{Node: "other-node", Taint: noExecute}))
+	})
+})
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("OSSelector", func() {
+	var (
+		ctx     context.Context
+		taint   corev1.Taint
+		linux   *corev1.Node
+		windows *corev1.Node
+	)
+
+	newNode := func(name string, labels map[string]string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		taint = corev1.Taint{Key: "os-specific", Effect: corev1.TaintEffectNoSchedule}
+		linux = newNode("linux", map[string]string{corev1.LabelOSStable: "linux", "pool": "gpu"})
+		windows = newNode("windows", map[string]string{corev1.LabelOSStable: "windows", "pool": "gpu"})
+	})
+
+	reconcileWith := func(specs ...nodesv1alpha1.TaintRemoverSpec) {
+		objs := []client.Object{linux, windows}
+		for i, spec := range specs {
+			objs = append(objs, &nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("remover-%d", i)},
+				Spec:       spec,
+			})
+		}
+		reconciler := newFakeReconciler(objs...)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(linux), linux)).To(Succeed())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(windows), windows)).To(Succeed())
+	}
+
+	It("should remove the taints only from the windows nodes", func() {
+		reconcileWith(nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}, OSSelector: "windows"})
+		Expect(windows.Spec.Taints).To(BeEmpty())
+		Expect(linux.Spec.Taints).To(HaveLen(1))
+	})
+
+	It("should remove the taints only from the linux nodes", func() {
+		reconcileWith(nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}, OSSelector: "linux"})
+		Expect(linux.Spec.Taints).To(BeEmpty())
+		Expect(windows.Spec.Taints).To(HaveLen(1))
+	})
+
+	It("should add to the node selector", func() {
+		reconcileWith(nodesv1alpha1.TaintRemoverSpec{
+			Taints:       []corev1.Taint{taint},
+			NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "cpu"}},
+			OSSelector:   "linux",
+		})
+		Expect(linux.Spec.Taints).To(HaveLen(1))
+		Expect(windows.Spec.Taints).To(HaveLen(1))
+	})
+
+	It("should keep the removers of each OS apart", func() {
+		reconcileWith(
+			nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}, OSSelector: "linux"},
+			nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}, OSSelector: "windows"},
+		)
+		Expect(linux.Spec.Taints).To(BeEmpty())
+		Expect(windows.Spec.Taints).To(BeEmpty())
+	})
+})
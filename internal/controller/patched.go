@@ -0,0 +1,80 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+)
+
+const (
+	// recentPatchTTL is how long a patched node resource version is remembered.
+	recentPatchTTL = 30 * time.Second
+	// recentPatchSize is the maximum number of remembered node patches.
+	recentPatchSize = 1024
+)
+
+// clockFunc adapts a function to the clock of the LRU cache.
+type clockFunc func() time.Time
+
+func (f clockFunc) Now() time.Time { return f() }
+
+// recentPatches returns the cache of recently patched node resource versions.
+func (r *TaintRemoverReconciler) recentPatches() *utilcache.LRUExpireCache {
+	r.patchedOnce.Do(func() {
+		r.patched = utilcache.NewLRUExpireCacheWithClock(recentPatchSize, clockFunc(r.currentTime))
+	})
+	return r.patched
+}
+
+// patchKey returns the key of the node's resource version. It is empty
+// when the resource version is unknown.
+func patchKey(node *corev1.Node) string {
+	if node.ResourceVersion == "" {
+		return ""
+	}
+	return node.Name + "/" + node.ResourceVersion
+}
+
+// recentlyPatched reports whether the node resource version of the key was
+// patched within the TTL, so that patching it again is redundant.
+func (r *TaintRemoverReconciler) recentlyPatched(key string) bool {
+	if key == "" {
+		return false
+	}
+	_, ok := r.recentPatches().Get(key)
+	return ok
+}
+
+// markPatched remembers that the node resource version of the key was patched.
+// The key must be taken before the patch updates the resource version.
+func (r *TaintRemoverReconciler) markPatched(key string) {
+	if key == "" {
+		return
+	}
+	r.recentPatches().Add(key, struct{}{}, recentPatchTTL)
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Recent patches", func() {
+	var (
+		ctx        context.Context
+		now        time.Time
+		c          *countingClient
+		reconciler *TaintRemoverReconciler
+		node       *corev1.Node
+		targets    []*removeTarget
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		now = time.Now()
+		taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		c = &countingClient{Client: newFakeClient(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		})}
+		reconciler = &TaintRemoverReconciler{Client: c, now: func() time.Time { return now }}
+		node = &corev1.Node{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: "test-node"}, node)).To(Succeed())
+		targets = []*removeTarget{{Taint: taint}}
+
+		result, err := reconciler.removeTaints(ctx, []*corev1.Node{node.DeepCopy()}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+	})
+
+	It("should skip the same resource version within the TTL", func() {
+		result, err := reconciler.removeTaints(ctx, []*corev1.Node{node.DeepCopy()}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(BeZero())
+		Expect(c.patches.Load()).To(Equal(int32(1)))
+	})
+
+	It("should process a new resource version", func() {
+		node.ResourceVersion += "0"
+		result, err := reconciler.removeTaints(ctx, []*corev1.Node{node.DeepCopy()}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+		Expect(c.patches.Load()).To(Equal(int32(2)))
+	})
+
+	It("should process the same resource version after the TTL", func() {
+		now = now.Add(recentPatchTTL + time.Second)
+		result, err := reconciler.removeTaints(ctx, []*corev1.Node{node.DeepCopy()}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+		Expect(c.patches.Load()).To(Equal(int32(2)))
+	})
+})
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("MaxPatchBytes", func() {
+	var (
+		taint corev1.Taint
+		large *corev1.Node
+		small *corev1.Node
+	)
+
+	BeforeEach(func() {
+		taint = corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		large = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "large"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		for i := 0; i < 100; i++ {
+			large.Spec.Taints = append(large.Spec.Taints, corev1.Taint{
+				Key: fmt.Sprintf("example.com/kept-%d", i), Effect: corev1.TaintEffectNoSchedule,
+			})
+		}
+		small = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "small"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+	})
+
+	It("should skip the node with an oversized patch and patch the others", func() {
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &TaintRemoverReconciler{
+			Client:        newFakeClient(large, small),
+			Recorder:      recorder,
+			MaxPatchBytes: 1024,
+		}
+		before := testutil.ToFloat64(oversizedPatches)
+		result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{large, small}, []*removeTarget{{Taint: taint}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+		Expect(result.NodesSkipped).To(Equal(1))
+		Expect(result.Failures).To(BeEmpty())
+		Expect(testutil.ToFloat64(oversizedPatches)).To(Equal(before + 1))
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " PatchTooLarge")))
+
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(large), large)).To(Succeed())
+		Expect(large.Spec.Taints).To(HaveLen(101))
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(small), small)).To(Succeed())
+		Expect(small.Spec.Taints).To(BeEmpty())
+	})
+
+	It("should not check the size when zero", func() {
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(large)}
+		result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{large}, []*removeTarget{{Taint: taint}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.NodesPatched).To(Equal(1))
+	})
+})
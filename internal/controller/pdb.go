@@ -0,0 +1,105 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=list
+
+// violatedPDB returns the name of the first PodDisruptionBudget that the
+// pods on the node would exceed when all disrupted, or "" when none would.
+func violatedPDB(ctx context.Context, c client.Reader, node *corev1.Node, pdbs []policyv1.PodDisruptionBudget) (string, error) {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.MatchingFields{podNodeNameField: node.Name}); err != nil {
+		return "", err
+	}
+	for i := range pdbs {
+		pdb := &pdbs[i]
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			return "", err
+		}
+		count := 0
+		for _, p := range pods.Items {
+			if p.Namespace == pdb.Namespace && selector.Matches(labels.Set(p.Labels)) {
+				count++
+			}
+		}
+		if count > 0 && count > int(pdb.Status.DisruptionsAllowed) {
+			return fmt.Sprintf("%s/%s", pdb.Namespace, pdb.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// pdbFilter returns a removal filter that keeps the NoExecute taints of
+// nodes whose pods would exceed a PodDisruptionBudget, marking the sweep
+// for a retry. The budgets are listed once per sweep and each node is
+// evaluated once. The taint is kept when the evaluation fails.
+func (r *TaintRemoverReconciler) pdbFilter(ctx context.Context) removalFilter {
+	logger := log.FromContext(ctx)
+	var pdbs *policyv1.PodDisruptionBudgetList
+	allowed := make(map[string]bool)
+	r.pdbPending.Store(false)
+	return func(node *corev1.Node, target *removeTarget) bool {
+		if !r.RespectPDB || target.Taint.Effect != corev1.TaintEffectNoExecute {
+			return true
+		}
+		if ok, seen := allowed[node.Name]; seen {
+			return ok
+		}
+		if pdbs == nil {
+			list := &policyv1.PodDisruptionBudgetList{}
+			if err := r.liveReader().List(ctx, list); err != nil {
+				checkForbidden(ctx, err, "list", "poddisruptionbudgets")
+				logger.Error(err, "Failed to list PodDisruptionBudgets")
+				return false
+			}
+			pdbs = list
+		}
+		violated, err := violatedPDB(ctx, r.liveReader(), node, pdbs.Items)
+		if err != nil {
+			logger.Error(err, "Failed to evaluate PodDisruptionBudgets", "node", node.Name)
+		} else if violated != "" {
+			logger.Info("Keeping NoExecute taint to respect PodDisruptionBudget", "node", node.Name,
+				"taint", target.Taint.ToString(), "pdb", violated)
+		}
+		allowed[node.Name] = err == nil && violated == ""
+		if !allowed[node.Name] {
+			r.pdbPending.Store(true)
+		}
+		return allowed[node.Name]
+	}
+}
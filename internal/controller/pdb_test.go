@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("RespectPDB", func() {
+	var (
+		taint corev1.Taint
+		node  *corev1.Node
+		pod   *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		taint = corev1.Taint{Key: "example.com/evict", Effect: corev1.TaintEffectNoExecute}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		pod = newPodOnNode("web", node.Name)
+		pod.Labels = map[string]string{"app": "web"}
+	})
+
+	newPDB := func(disruptionsAllowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: "web"},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			},
+			Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+		}
+	}
+
+	removeWith := func(objs ...client.Object) (int, *TaintRemoverReconciler) {
+		reconciler := newFakeReconciler(append([]client.Object{node, pod}, objs...)...)
+		reconciler.ConfirmNoExecute = true
+		reconciler.RespectPDB = true
+		result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+		Expect(err).NotTo(HaveOccurred())
+		return result.TaintsRemoved, reconciler
+	}
+
+	It("should keep the taint and requeue when a PDB would be violated", func() {
+		removed, reconciler := removeWith(newPDB(0))
+		Expect(removed).To(BeZero())
+		Expect(reconciler.nextRequeue()).To(Equal(gatedRequeue))
+	})
+
+	It("should remove the taint when the PDB allows the disruption", func() {
+		removed, reconciler := removeWith(newPDB(1))
+		Expect(removed).To(Equal(1))
+		Expect(reconciler.nextRequeue()).To(BeZero())
+	})
+
+	It("should remove the taint without a matching PDB", func() {
+		removed, _ := removeWith()
+		Expect(removed).To(Equal(1))
+	})
+})
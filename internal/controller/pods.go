@@ -0,0 +1,118 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	tutil "github.com/norseto/taint-remover/internal/taints"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+//+kubebuilder:rbac:groups="",resources=pods,verbs=list
+
+// podNodeNameField is the field selector used to list the pods on a node.
+const podNodeNameField = "spec.nodeName"
+
+// countAffectedPods counts the pods on the node that do not tolerate the taint.
+// Pods in the ignored namespaces are not counted.
+func countAffectedPods(ctx context.Context, c client.Reader, node *corev1.Node, taint *corev1.Taint,
+	ignoreNamespaces []string) (int, error) {
+	pods := &corev1.PodList{}
+	err := c.List(ctx, pods, client.MatchingFields{podNodeNameField: node.Name})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, p := range pods.Items {
+		if slices.Contains(ignoreNamespaces, p.Namespace) {
+			continue
+		}
+		if !toleratesTaint(p.Spec.Tolerations, taint) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// toleratesTaint checks if any of the tolerations tolerates the taint.
+func toleratesTaint(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for _, t := range tolerations {
+		if t.ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// logAffectedPods logs the number of pods on the node that do not tolerate
+// each NoExecute taint about to be removed. It is best-effort and never fails.
+func (r *TaintRemoverReconciler) logAffectedPods(ctx context.Context, node *corev1.Node, newTaints []corev1.Taint) {
+	logger := log.FromContext(ctx)
+
+	_, removing := tutil.TaintSetDiff(newTaints, node.Spec.Taints)
+	for _, t := range removing {
+		if t.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		count, err := countAffectedPods(ctx, r.liveReader(), node, t, r.IgnoreNamespaces)
+		if err != nil {
+			logger.Error(err, "Failed to count affected pods", "node", node.Name)
+			continue
+		}
+		logger.Info("Removing NoExecute taint", "node", node.Name, "taint", t.ToString(), "affected pods", count)
+	}
+}
+
+// protectNoExecuteFilter returns a removal filter that keeps the NoExecute
+// taints of nodes hosting pods without a matching toleration, emitting a
+// Warning event on the node. The taint is kept when pods cannot be listed.
+func (r *TaintRemoverReconciler) protectNoExecuteFilter(ctx context.Context) removalFilter {
+	logger := log.FromContext(ctx)
+	return func(node *corev1.Node, target *removeTarget) bool {
+		if !r.ProtectNoExecuteWithPods || target.Taint.Effect != corev1.TaintEffectNoExecute {
+			return true
+		}
+		count, err := countAffectedPods(ctx, r.liveReader(), node, &target.Taint, r.IgnoreNamespaces)
+		if err != nil {
+			logger.Error(err, "Failed to count affected pods", "node", node.Name)
+			return false
+		}
+		if count == 0 {
+			return true
+		}
+		logger.Info("Keeping NoExecute taint protecting pods", "node", node.Name,
+			"taint", target.Taint.ToString(), "affected pods", count)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(nodeReference(node), corev1.EventTypeWarning, "NoExecuteProtected",
+				"Keeping taint %s: %d pods do not tolerate it", target.Taint.ToString(), count)
+		}
+		return false
+	}
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("affected pods", func() {
+	var (
+		taint corev1.Taint
+		node  *corev1.Node
+		objs  []client.Object
+	)
+
+	BeforeEach(func() {
+		taint = corev1.Taint{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoExecute}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		objs = []client.Object{
+			node,
+			newPodOnNode("tolerating", node.Name, corev1.Toleration{
+				Key: "foo", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute,
+			}),
+			newPodOnNode("intolerant-1", node.Name),
+			newPodOnNode("intolerant-2", node.Name),
+			newPodOnNode("elsewhere", "other-node"),
+		}
+	})
+
+	Describe("countAffectedPods", func() {
+		It("should count pods on the node without a matching toleration", func() {
+			count, err := countAffectedPods(context.TODO(), newFakeClient(objs...), node, &taint, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+		})
+
+		It("should not count pods in ignored namespaces", func() {
+			system := newPodOnNode("system", node.Name)
+			system.Namespace = "kube-system"
+			objs = append(objs, system)
+			count, err := countAffectedPods(context.TODO(), newFakeClient(objs...), node, &taint, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(3))
+
+			count, err = countAffectedPods(context.TODO(), newFakeClient(objs...), node, &taint, []string{"kube-system"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+		})
+	})
+
+	Describe("removeTaints", func() {
+		It("should log the affected pod count when enabled", func() {
+			var lines []string
+			logger := funcr.New(func(prefix, args string) {
+				lines = append(lines, args)
+			}, funcr.Options{})
+			ctx := log.IntoContext(context.TODO(), logger)
+
+			reconciler := &TaintRemoverReconciler{Client: newFakeClient(objs...), LogAffectedPods: true, ConfirmNoExecute: true}
+			result, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.NodesPatched).To(Equal(1))
+			Expect(strings.Join(lines, "\n")).To(ContainSubstring(`"affected pods"=2`))
+		})
+
+		It("should not look up pods when disabled", func() {
+			var lines []string
+			logger := funcr.New(func(prefix, args string) {
+				lines = append(lines, args)
+			}, funcr.Options{})
+			ctx := log.IntoContext(context.TODO(), logger)
+
+			reconciler := newFakeReconciler(objs...)
+			_, err := reconciler.removeTaints(ctx, []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Join(lines, "\n")).NotTo(ContainSubstring("affected pods"))
+		})
+
+		It("should keep NoExecute taints protecting intolerant pods", func() {
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &TaintRemoverReconciler{
+				Client:                   newFakeClient(objs...),
+				ConfirmNoExecute:         true,
+				ProtectNoExecuteWithPods: true,
+				Recorder:                 recorder,
+			}
+			result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.NodesPatched).To(BeZero())
+			Expect(result.NodesSkipped).To(Equal(1))
+			Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " NoExecuteProtected")))
+		})
+
+		It("should remove NoExecute taints when all pods tolerate them", func() {
+			reconciler := &TaintRemoverReconciler{
+				Client:                   newFakeClient(objs[:2]...),
+				ConfirmNoExecute:         true,
+				ProtectNoExecuteWithPods: true,
+			}
+			result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, []*removeTarget{{Taint: taint}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.NodesPatched).To(Equal(1))
+		})
+	})
+})
+
+// newPodOnNode returns a pod scheduled on the node with the given tolerations.
+func newPodOnNode(name, nodeName string, tolerations ...corev1.Toleration) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName:    nodeName,
+			Tolerations: tolerations,
+		},
+	}
+}
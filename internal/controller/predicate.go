@@ -0,0 +1,99 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// nodeChangedPredicate filters out the node updates that cannot change the
+// removals, such as status heartbeats. An update passes when the taints, the
+// schedulability, the labels, the condition statuses or the boot ID of the
+// node change, as well as the node annotations and the heartbeat freshness
+// the reconciler is configured to read.
+func (r *TaintRemoverReconciler) nodeChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok {
+				return true
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok {
+				return true
+			}
+			return r.nodeChanged(oldNode, newNode)
+		},
+	}
+}
+
+// nodeChanged reports whether the removals may differ between both nodes.
+func (r *TaintRemoverReconciler) nodeChanged(oldNode, newNode *corev1.Node) bool {
+	return !tutil.TaintSetsEqual(oldNode.Spec.Taints, newNode.Spec.Taints) ||
+		oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable ||
+		!equality.Semantic.DeepEqual(oldNode.Labels, newNode.Labels) ||
+		!equality.Semantic.DeepEqual(conditionStatuses(oldNode), conditionStatuses(newNode)) ||
+		oldNode.Status.NodeInfo.BootID != newNode.Status.NodeInfo.BootID ||
+		r.watchedAnnotationsChanged(oldNode, newNode) ||
+		r.heartbeatFresh(oldNode, nil) != r.heartbeatFresh(newNode, nil)
+}
+
+// watchedAnnotations returns the node annotations read by the removal
+// filters: the node group and machine owner annotations, and the managed
+// keys with OnlyManageOwn.
+func (r *TaintRemoverReconciler) watchedAnnotations() []string {
+	keys := []string{r.nodeGroupKey(), r.machineOwnerKey()}
+	if r.OnlyManageOwn {
+		keys = append(keys, nodesv1alpha1.ManagedKeysAnnotation)
+	}
+	return keys
+}
+
+// watchedAnnotationsChanged reports whether any of the watched annotations
+// differs between both nodes.
+func (r *TaintRemoverReconciler) watchedAnnotationsChanged(oldNode, newNode *corev1.Node) bool {
+	for _, key := range r.watchedAnnotations() {
+		if oldNode.Annotations[key] != newNode.Annotations[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionStatuses returns the statuses of the node conditions by type,
+// leaving out the heartbeat and transition times.
+func conditionStatuses(node *corev1.Node) map[corev1.NodeConditionType]corev1.ConditionStatus {
+	statuses := make(map[corev1.NodeConditionType]corev1.ConditionStatus, len(node.Status.Conditions))
+	for _, c := range node.Status.Conditions {
+		statuses[c.Type] = c.Status
+	}
+	return statuses
+}
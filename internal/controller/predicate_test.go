@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("nodeChangedPredicate", func() {
+	var (
+		oldNode    *corev1.Node
+		reconciler *TaintRemoverReconciler
+	)
+
+	BeforeEach(func() {
+		reconciler = &TaintRemoverReconciler{}
+		oldNode = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node", ResourceVersion: "1"},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+				{Key: "a", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "b", Effect: corev1.TaintEffectNoExecute},
+			}},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: metav1.NewTime(time.Unix(0, 0))},
+			}},
+		}
+	})
+
+	passes := func(update func(n *corev1.Node)) bool {
+		newNode := oldNode.DeepCopy()
+		newNode.ResourceVersion = "2"
+		update(newNode)
+		return reconciler.nodeChangedPredicate().Update(event.UpdateEvent{ObjectOld: oldNode, ObjectNew: newNode})
+	}
+
+	It("should filter heartbeat-only updates", func() {
+		Expect(passes(func(n *corev1.Node) {
+			n.Status.Conditions[0].LastHeartbeatTime = metav1.NewTime(time.Unix(60, 0))
+		})).To(BeFalse())
+	})
+
+	It("should filter reordered taints", func() {
+		Expect(passes(func(n *corev1.Node) {
+			n.Spec.Taints[0], n.Spec.Taints[1] = n.Spec.Taints[1], n.Spec.Taints[0]
+		})).To(BeFalse())
+	})
+
+	It("should pass taint changes", func() {
+		Expect(passes(func(n *corev1.Node) {
+			n.Spec.Taints = n.Spec.Taints[:1]
+		})).To(BeTrue())
+	})
+
+	It("should pass schedulability changes", func() {
+		Expect(passes(func(n *corev1.Node) { n.Spec.Unschedulable = true })).To(BeTrue())
+	})
+
+	It("should pass label changes", func() {
+		Expect(passes(func(n *corev1.Node) { n.Labels = map[string]string{"zone": "a"} })).To(BeTrue())
+	})
+
+	It("should pass condition status changes", func() {
+		Expect(passes(func(n *corev1.Node) { n.Status.Conditions[0].Status = corev1.ConditionFalse })).To(BeTrue())
+	})
+
+	It("should pass boot ID changes", func() {
+		oldNode.Status.NodeInfo.BootID = "boot-1"
+		Expect(passes(func(n *corev1.Node) { n.Status.NodeInfo.BootID = "boot-2" })).To(BeTrue())
+	})
+
+	It("should filter unrelated annotation changes", func() {
+		Expect(passes(func(n *corev1.Node) {
+			n.Annotations = map[string]string{"example.com/other": "changed"}
+		})).To(BeFalse())
+	})
+
+	It("should pass node group annotation changes", func() {
+		Expect(passes(func(n *corev1.Node) {
+			n.Annotations = map[string]string{defaultNodeGroupAnnotationKey: "group-a"}
+		})).To(BeTrue())
+	})
+
+	It("should pass changes of the configured node group annotation", func() {
+		reconciler.NodeGroupAnnotationKey = "example.com/pool"
+		Expect(passes(func(n *corev1.Node) {
+			n.Annotations = map[string]string{"example.com/pool": "pool-a"}
+		})).To(BeTrue())
+	})
+
+	It("should pass machine owner annotation changes", func() {
+		Expect(passes(func(n *corev1.Node) {
+			n.Annotations = map[string]string{defaultMachineOwnerAnnotationKey: "md-a"}
+		})).To(BeTrue())
+	})
+
+	It("should pass managed keys annotation changes only when managing own taints", func() {
+		update := func(n *corev1.Node) {
+			n.Annotations = map[string]string{nodesv1alpha1.ManagedKeysAnnotation: "a"}
+		}
+		Expect(passes(update)).To(BeFalse())
+		reconciler.OnlyManageOwn = true
+		Expect(passes(update)).To(BeTrue())
+	})
+
+	Context("When the heartbeat staleness is limited", func() {
+		BeforeEach(func() {
+			reconciler.MaxHeartbeatStaleness = time.Minute
+			reconciler.now = func() time.Time { return time.Unix(600, 0) }
+		})
+
+		It("should pass a heartbeat turning the node fresh", func() {
+			Expect(passes(func(n *corev1.Node) {
+				n.Status.Conditions[0].LastHeartbeatTime = metav1.NewTime(time.Unix(590, 0))
+			})).To(BeTrue())
+		})
+
+		It("should filter a heartbeat of a node already fresh", func() {
+			oldNode.Status.Conditions[0].LastHeartbeatTime = metav1.NewTime(time.Unix(580, 0))
+			Expect(passes(func(n *corev1.Node) {
+				n.Status.Conditions[0].LastHeartbeatTime = metav1.NewTime(time.Unix(590, 0))
+			})).To(BeFalse())
+		})
+	})
+})
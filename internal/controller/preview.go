@@ -0,0 +1,103 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// defaultMaxPreviewNodes is the number of nodes previewed per remover when
+// MaxPreviewNodes is not set.
+const defaultMaxPreviewNodes = 10
+
+// previewRemovals holds the taints ObserveOnly removers would remove in a
+// sweep, by remover and node name.
+type previewRemovals map[string]map[string][]corev1.Taint
+
+// observeFilter returns a removal filter that keeps the taints of ObserveOnly
+// targets, recording them into previews instead.
+func observeFilter(previews previewRemovals) removalFilter {
+	return func(node *corev1.Node, target *removeTarget) bool {
+		if !target.ObserveOnly {
+			return true
+		}
+		nodes := previews[target.remover]
+		if nodes == nil {
+			nodes = make(map[string][]corev1.Taint)
+			previews[target.remover] = nodes
+		}
+		if !tutil.TaintExists(nodes[node.Name], &target.Taint) {
+			nodes[node.Name] = append(nodes[node.Name], target.Taint)
+		}
+		return false
+	}
+}
+
+// nodeDiff returns the preview of the taints removed from the node.
+func nodeDiff(node string, taints []corev1.Taint) nodesv1alpha1.NodeDiff {
+	lines := make([]string, 0, len(taints))
+	for _, t := range taints {
+		lines = append(lines, "-"+t.ToString())
+	}
+	slices.Sort(lines)
+	return nodesv1alpha1.NodeDiff{Node: node, Diff: strings.Join(lines, "\n")}
+}
+
+// maxPreviewNodes returns the number of nodes previewed per remover.
+func (r *TaintRemoverReconciler) maxPreviewNodes() int {
+	if r.MaxPreviewNodes > 0 {
+		return r.MaxPreviewNodes
+	}
+	return defaultMaxPreviewNodes
+}
+
+// previewDiffs returns the preview diffs of the remover with the previews of
+// the processed nodes. Entries for the other nodes are kept, and the previews
+// are capped to the first nodes by name.
+func (r *TaintRemoverReconciler) previewDiffs(remover *nodesv1alpha1.TaintRemover, processed map[string]bool,
+	previews previewRemovals) []nodesv1alpha1.NodeDiff {
+	var entries []nodesv1alpha1.NodeDiff
+	for _, e := range remover.Status.PreviewDiffs {
+		if !processed[e.Node] {
+			entries = append(entries, e)
+		}
+	}
+	for node, taints := range previews[remover.Name] {
+		entries = append(entries, nodeDiff(node, taints))
+	}
+	slices.SortFunc(entries, func(a, b nodesv1alpha1.NodeDiff) int {
+		return strings.Compare(a.Node, b.Node)
+	})
+	if len(entries) > r.maxPreviewNodes() {
+		entries = entries[:r.maxPreviewNodes()]
+	}
+	return entries
+}
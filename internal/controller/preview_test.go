@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("PreviewDiffs", func() {
+	var (
+		ctx     context.Context
+		spot    corev1.Taint
+		other   corev1.Taint
+		remover *nodesv1alpha1.TaintRemover
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		spot = corev1.Taint{Key: "cloud.example.com/spot", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+		other = corev1.Taint{Key: "other", Effect: corev1.TaintEffectNoExecute}
+		remover = &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "observer"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				Taints:      []corev1.Taint{spot, other},
+				ObserveOnly: true,
+			},
+		}
+	})
+
+	newNodes := func(count int) []client.Object {
+		var objs []client.Object
+		for i := 0; i < count; i++ {
+			objs = append(objs, &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{spot, other}},
+			})
+		}
+		return objs
+	}
+
+	It("should preview the removals without performing them", func() {
+		objs := append(newNodes(1), remover)
+		reconciler := newFakeReconciler(objs...)
+		reconciler.ConfirmNoExecute = true
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+
+		node := &corev1.Node{}
+		Expect(reconciler.Get(ctx, client.ObjectKey{Name: "node-0"}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(ConsistOf(spot, other))
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(remover), remover)).To(Succeed())
+		Expect(remover.Status.PreviewDiffs).To(Equal([]nodesv1alpha1.NodeDiff{
+			{Node: "node-0", Diff: "-cloud.example.com/spot=true:NoSchedule\n-other:NoExecute"},
+		}))
+	})
+
+	It("should cap the previewed nodes", func() {
+		objs := append(newNodes(3), remover)
+		reconciler := newFakeReconciler(objs...)
+		reconciler.MaxPreviewNodes = 2
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(remover), remover)).To(Succeed())
+		var previewed []string
+		for _, d := range remover.Status.PreviewDiffs {
+			previewed = append(previewed, d.Node)
+		}
+		Expect(previewed).To(Equal([]string{"node-0", "node-1"}))
+	})
+})
@@ -0,0 +1,105 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// deletedRemovers holds the TaintRemovers deleted since the last sweep.
+type deletedRemovers struct {
+	mu       sync.Mutex
+	removers []*nodesv1alpha1.TaintRemover
+}
+
+// add records the deleted remover.
+func (d *deletedRemovers) add(remover *nodesv1alpha1.TaintRemover) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.removers = append(d.removers, remover.DeepCopy())
+}
+
+// take returns the recorded removers and forgets them.
+func (d *deletedRemovers) take() []*nodesv1alpha1.TaintRemover {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	removers := d.removers
+	d.removers = nil
+	return removers
+}
+
+// removerDeletedPredicate records the deleted TaintRemovers for the next
+// sweep to report. It lets every event through.
+func (r *TaintRemoverReconciler) removerDeletedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			if remover, ok := e.Object.(*nodesv1alpha1.TaintRemover); ok {
+				r.deleted.add(remover)
+			}
+			return true
+		},
+	}
+}
+
+// removerTaints describes the taints listed by the remover.
+func removerTaints(remover *nodesv1alpha1.TaintRemover) []string {
+	var taints []string
+	for _, t := range remover.Spec.Taints {
+		taints = append(taints, t.ToString())
+	}
+	for _, ke := range remover.Spec.TaintKeyEffects {
+		for _, effect := range ke.Effects {
+			taints = append(taints, ke.Key+":"+string(effect))
+		}
+	}
+	return taints
+}
+
+// reportDeletedRemovers logs the taints of the removers deleted since the
+// last sweep, which are no longer removed. The taints already removed are
+// not restored. A Normal event is emitted on each remover with
+// RemoverDeletionEvents.
+func (r *TaintRemoverReconciler) reportDeletedRemovers(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	for _, remover := range r.deleted.take() {
+		taints := removerTaints(remover)
+		logger.Info("TaintRemover deleted, its taints are no longer removed", "remover", remover.Name,
+			"taints", taints)
+		if r.RemoverDeletionEvents && r.Recorder != nil {
+			r.Recorder.Eventf(remover, corev1.EventTypeNormal, "RemoverDeleted",
+				"Taints %s are no longer removed, the taints already removed are not restored",
+				strings.Join(taints, ", "))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("TaintRemover deletion", func() {
+	var (
+		ctx      context.Context
+		lines    []string
+		recorder *record.FakeRecorder
+		remover  *nodesv1alpha1.TaintRemover
+	)
+
+	BeforeEach(func() {
+		lines = nil
+		logger := funcr.New(func(prefix, args string) {
+			lines = append(lines, args)
+		}, funcr.Options{})
+		ctx = log.IntoContext(context.TODO(), logger)
+		recorder = record.NewFakeRecorder(10)
+		remover = &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "deleted"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				Taints: []corev1.Taint{{Key: "foo", Effect: corev1.TaintEffectNoSchedule}},
+				TaintKeyEffects: []nodesv1alpha1.TaintKeyEffects{
+					{Key: "bar", Effects: []corev1.TaintEffect{corev1.TaintEffectNoExecute}},
+				},
+			},
+		}
+	})
+
+	deleteAndReconcile := func(reconciler *TaintRemoverReconciler) {
+		Expect(reconciler.removerDeletedPredicate().Delete(event.DeleteEvent{Object: remover})).To(BeTrue())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("should log the taints no longer removed once", func() {
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(), Recorder: recorder}
+		deleteAndReconcile(reconciler)
+		logs := strings.Join(lines, "\n")
+		Expect(logs).To(ContainSubstring("TaintRemover deleted"))
+		Expect(logs).To(ContainSubstring(`"remover"="deleted"`))
+		Expect(logs).To(ContainSubstring(`"taints"=["foo:NoSchedule" "bar:NoExecute"]`))
+		Expect(recorder.Events).To(BeEmpty())
+
+		lines = nil
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.Join(lines, "\n")).NotTo(ContainSubstring("TaintRemover deleted"))
+	})
+
+	It("should emit an event when enabled", func() {
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(), Recorder: recorder, RemoverDeletionEvents: true}
+		deleteAndReconcile(reconciler)
+		Expect(recorder.Events).To(Receive(Equal(corev1.EventTypeNormal +
+			" RemoverDeleted Taints foo:NoSchedule, bar:NoExecute are no longer removed, " +
+			"the taints already removed are not restored")))
+	})
+
+	It("should let the other events through without recording them", func() {
+		reconciler := &TaintRemoverReconciler{}
+		Expect(reconciler.removerDeletedPredicate().Create(event.CreateEvent{Object: remover})).To(BeTrue())
+		Expect(reconciler.deleted.take()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,99 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NodeFailure describes a node that could not be patched.
+type NodeFailure struct {
+	Node string
+	Err  error
+}
+
+// RemovalResult is the accounting of a taint removal over a set of nodes.
+type RemovalResult struct {
+	// NodesPatched is the number of nodes patched.
+	NodesPatched int
+	// TaintsRemoved is the number of taints removed from the patched nodes.
+	TaintsRemoved int
+	// NodesSkipped is the number of nodes left untouched because no taint
+	// was removable or they were patched recently.
+	NodesSkipped int
+	// Failures lists the nodes whose patch failed.
+	Failures []NodeFailure
+}
+
+// keysAndValues returns the result as logger key/value pairs.
+func (res *RemovalResult) keysAndValues() []any {
+	return []any{
+		"patched", res.NodesPatched,
+		"removed", res.TaintsRemoved,
+		"skipped", res.NodesSkipped,
+		"failures", len(res.Failures),
+	}
+}
+
+// PartialRemovalError is returned when patching failed on some nodes but
+// succeeded on others.
+type PartialRemovalError struct {
+	succeeded int
+	errs      []error
+}
+
+// newPartialRemovalError returns a PartialRemovalError for the result, or nil
+// when no node failed or none succeeded.
+func newPartialRemovalError(result *RemovalResult) *PartialRemovalError {
+	if len(result.Failures) == 0 || result.NodesPatched == 0 {
+		return nil
+	}
+	errs := make([]error, 0, len(result.Failures))
+	for _, f := range result.Failures {
+		errs = append(errs, fmt.Errorf("node %s: %w", f.Node, f.Err))
+	}
+	return &PartialRemovalError{succeeded: result.NodesPatched, errs: errs}
+}
+
+// Succeeded returns the number of nodes patched.
+func (e *PartialRemovalError) Succeeded() int {
+	return e.succeeded
+}
+
+// Failed returns the number of nodes whose patch failed.
+func (e *PartialRemovalError) Failed() int {
+	return len(e.errs)
+}
+
+func (e *PartialRemovalError) Error() string {
+	return fmt.Sprintf("taint removal failed on %d of %d nodes: %v",
+		e.Failed(), e.Succeeded()+e.Failed(), errors.Join(e.errs...))
+}
+
+// Unwrap returns the node errors.
+func (e *PartialRemovalError) Unwrap() []error {
+	return e.errs
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	goerrors "errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("PartialRemovalError", func() {
+	var (
+		taint corev1.Taint
+		nodes []*corev1.Node
+	)
+
+	BeforeEach(func() {
+		taint = corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		nodes = nil
+		for _, name := range []string{"node-a", "node-b", "node-c"} {
+			nodes = append(nodes, &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+			})
+		}
+	})
+
+	It("should be returned when some nodes failed", func() {
+		c := &blockingPatchClient{Client: newFakeClient(nodes[0], nodes[1], nodes[2]), block: "node-b"}
+		reconciler := &TaintRemoverReconciler{Client: c, PatchTimeout: 10 * time.Millisecond}
+
+		_, err := reconciler.removeTaints(context.TODO(), nodes, []*removeTarget{{Taint: taint}})
+		var partial *PartialRemovalError
+		Expect(goerrors.As(err, &partial)).To(BeTrue())
+		Expect(partial.Succeeded()).To(Equal(2))
+		Expect(partial.Failed()).To(Equal(1))
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(err.Error()).To(ContainSubstring("node node-b"))
+	})
+
+	It("should collect the failures of every node", func() {
+		patchErr := goerrors.New("patch failed")
+		c := &erroringClient{
+			Client: newFakeClient(nodes[0], nodes[1], nodes[2]),
+			patchErr: func(obj client.Object) error {
+				if obj.GetName() == "node-b" {
+					return nil
+				}
+				return patchErr
+			},
+		}
+		reconciler := &TaintRemoverReconciler{Client: c}
+
+		result, err := reconciler.removeTaints(context.TODO(), nodes, []*removeTarget{{Taint: taint}})
+		var partial *PartialRemovalError
+		Expect(goerrors.As(err, &partial)).To(BeTrue())
+		Expect(partial.Succeeded()).To(Equal(1))
+		Expect(partial.Failed()).To(Equal(2))
+		Expect(err).To(MatchError(patchErr))
+		Expect(err.Error()).To(And(ContainSubstring("node node-a"), ContainSubstring("node node-c")))
+		Expect(result.Failures).To(HaveLen(2))
+	})
+
+	It("should return every failure when every node failed", func() {
+		reconciler := &TaintRemoverReconciler{Client: newForbiddenPatchClient(nodes[0], nodes[1])}
+
+		result, err := reconciler.removeTaints(context.TODO(), nodes[:2], []*removeTarget{{Taint: taint}})
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		Expect(err.Error()).To(And(ContainSubstring("node node-a"), ContainSubstring("node node-b")))
+		Expect(result.Failures).To(HaveLen(2))
+	})
+
+	It("should not be returned when every node failed", func() {
+		reconciler := &TaintRemoverReconciler{Client: newForbiddenPatchClient(nodes[0])}
+
+		_, err := reconciler.removeTaints(context.TODO(), nodes[:1], []*removeTarget{{Taint: taint}})
+		var partial *PartialRemovalError
+		Expect(goerrors.As(err, &partial)).To(BeFalse())
+		Expect(apierrors.IsForbidden(err)).To(BeTrue())
+	})
+
+	It("should requeue the sweep without an error", func() {
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}},
+		}
+		c := &blockingPatchClient{Client: newFakeClient(nodes[0], nodes[1], tr), block: "node-b"}
+		reconciler := &TaintRemoverReconciler{Client: c, PatchTimeout: 10 * time.Millisecond}
+
+		result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(partialRemovalRequeue))
+	})
+})
+
+var _ = Describe("OnReconcileComplete", func() {
+	It("should be called with the result of each reconcile", func() {
+		taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}},
+		}
+		results := make(chan RemovalResult, 2)
+		reconciler := &TaintRemoverReconciler{
+			Client:              newFakeClient(node, tr),
+			OnReconcileComplete: func(res RemovalResult) { results <- res },
+		}
+
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(Receive(Equal(RemovalResult{NodesPatched: 1, TaintsRemoved: 1})))
+
+		// Nothing is left to remove, so the next reconcile returns early.
+		_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(Receive(Equal(RemovalResult{})))
+	})
+})
@@ -0,0 +1,90 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// sweepStatus is the summary of the sweeps served by the status handler.
+type sweepStatus struct {
+	LastReconcileTime    *time.Time `json:"lastReconcileTime,omitempty"`
+	LastError            string     `json:"lastError,omitempty"`
+	TaintsRemovedTotal   int        `json:"taintsRemovedTotal"`
+	TaintedNodesObserved int        `json:"taintedNodesObserved"`
+	Standby              bool       `json:"standby,omitempty"`
+}
+
+// statusTracker tracks the sweep status of the reconciler.
+type statusTracker struct {
+	mu     sync.Mutex
+	status sweepStatus
+}
+
+// recordSweep records the outcome of a sweep finished at now. The last
+// reconcile time is only updated when the sweep succeeded.
+func (s *statusTracker) recordSweep(now time.Time, taintedNodes, removed int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status.TaintedNodesObserved = taintedNodes
+	s.status.TaintsRemovedTotal += removed
+	if err != nil {
+		s.status.LastError = err.Error()
+		return
+	}
+	s.status.LastError = ""
+	s.status.LastReconcileTime = &now
+}
+
+// addRemoved adds taints removed outside of a sweep to the total.
+func (s *statusTracker) addRemoved(removed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status.TaintsRemovedTotal += removed
+}
+
+// snapshot returns a copy of the current status.
+func (s *statusTracker) snapshot() sweepStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.status
+}
+
+// StatusHandler returns an HTTP handler serving the sweep status as JSON.
+// A standby replica reports it, as it is ready without sweeping.
+func (r *TaintRemoverReconciler) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		status := r.status.snapshot()
+		status.Standby = !r.elected()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("StatusHandler", func() {
+	It("should report the last sweep", func() {
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		foo := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		bar := corev1.Taint{Key: "bar", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{foo, bar}},
+		}
+		kept := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "kept-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoSchedule}}},
+		}
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{foo, bar}},
+		}
+		reconciler := newFakeReconciler(node, kept, tr)
+		reconciler.now = func() time.Time { return now }
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+
+		rec := httptest.NewRecorder()
+		reconciler.StatusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var status map[string]any
+		Expect(json.Unmarshal(rec.Body.Bytes(), &status)).To(Succeed())
+		Expect(status).To(Equal(map[string]any{
+			"lastReconcileTime":    "2024-01-01T00:00:00Z",
+			"taintsRemovedTotal":   float64(2),
+			"taintedNodesObserved": float64(2),
+		}))
+	})
+
+	It("should report the last error", func() {
+		reconciler := &TaintRemoverReconciler{}
+		reconciler.status.recordSweep(time.Now(), 1, 0, context.DeadlineExceeded)
+
+		rec := httptest.NewRecorder()
+		reconciler.StatusHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+		var status sweepStatus
+		Expect(json.Unmarshal(rec.Body.Bytes(), &status)).To(Succeed())
+		Expect(status.LastError).To(Equal(context.DeadlineExceeded.Error()))
+		Expect(status.LastReconcileTime).To(BeNil())
+	})
+})
@@ -0,0 +1,93 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// removalSummary counts the taints a remover had removed in a sweep and the
+// nodes they were removed from.
+type removalSummary struct {
+	taints int
+	nodes  int
+}
+
+// summarizeRemovals returns the removal summaries of the sweep by remover.
+// Only the removals from the nodes patched successfully are counted.
+func summarizeRemovals(removals taintRemovals, patched map[string]nodesv1alpha1.TaintOutcome) map[string]removalSummary {
+	summaries := map[string]removalSummary{}
+	for node, entries := range removals {
+		if patched[node] != nodesv1alpha1.TaintOutcomeRemoved {
+			continue
+		}
+		counted := map[string]bool{}
+		for _, e := range entries {
+			s := summaries[e.remover]
+			s.taints++
+			if !counted[e.remover] {
+				counted[e.remover] = true
+				s.nodes++
+			}
+			summaries[e.remover] = s
+		}
+	}
+	return summaries
+}
+
+// reportRemovalSummaries emits a Normal event on each remover that had
+// taints removed in the sweep. Nothing is emitted without a recorder.
+func (r *TaintRemoverReconciler) reportRemovalSummaries(ctx context.Context, removals taintRemovals,
+	patched map[string]nodesv1alpha1.TaintOutcome) error {
+	if r.Recorder == nil {
+		return nil
+	}
+	summaries := summarizeRemovals(removals, patched)
+	if len(summaries) < 1 {
+		return nil
+	}
+	removers := &nodesv1alpha1.TaintRemoverList{}
+	if err := r.List(ctx, removers, r.removerListOptions()...); err != nil {
+		if isMissingCRD(err) {
+			return nil
+		}
+		checkForbidden(ctx, err, "list", "taintremovers")
+		return err
+	}
+	for i := range removers.Items {
+		remover := &removers.Items[i]
+		s, ok := summaries[remover.Name]
+		if !ok {
+			continue
+		}
+		r.Recorder.Eventf(remover, corev1.EventTypeNormal, "TaintsRemoved",
+			"Removed %d taints from %d nodes", s.taints, s.nodes)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("removal summary event", func() {
+	var (
+		ctx      context.Context
+		first    corev1.Taint
+		second   corev1.Taint
+		recorder *record.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		first = corev1.Taint{Key: "example.com/first", Effect: corev1.TaintEffectNoSchedule}
+		second = corev1.Taint{Key: "example.com/second", Effect: corev1.TaintEffectNoSchedule}
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	newNode := func(name string, taints ...corev1.Taint) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Taints: taints},
+		}
+	}
+
+	It("should summarize the removals on the remover", func() {
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{first, second}},
+		}
+		c := newFakeClient(newNode("node-a", first, second), newNode("node-b", first), tr)
+		_, err := (&TaintRemoverReconciler{Client: c, Recorder: recorder}).Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(Receive(Equal(corev1.EventTypeNormal + " TaintsRemoved Removed 3 taints from 2 nodes")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should not emit an event when nothing is removed", func() {
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{second}},
+		}
+		c := newFakeClient(newNode("node-a", first), tr)
+		_, err := (&TaintRemoverReconciler{Client: c, Recorder: recorder}).Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
@@ -25,30 +25,185 @@ SOFTWARE.
 package controller
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	tutil "github.com/norseto/taint-remover/internal/taints"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
 )
 
+// errPatchTooLarge is returned by patchNode for patches exceeding
+// MaxPatchBytes.
+var errPatchTooLarge = goerrors.New("node patch too large")
+
+// partialRemovalRequeue is the interval after which a sweep that failed on
+// some nodes is retried.
+const partialRemovalRequeue = 10 * time.Second
+
+// cacheSyncRequeue is the interval after which a sweep that found no tainted
+// node before the cache got synced is retried.
+const cacheSyncRequeue = 2 * time.Second
+
 // TaintRemoverReconciler reconciles a TaintRemover object
 type TaintRemoverReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Cache is waited on before the first sweep so that it sees the real
+	// node set. Waiting is skipped when nil.
+	Cache cache.Cache
+	// CacheSyncTimeout bounds the wait for the Cache, after which the sweep
+	// goes on and is requeued shortly if it finds no tainted node. The wait
+	// is not bounded when zero.
+	CacheSyncTimeout time.Duration
+	// PatchTimeout bounds each node patch. No timeout is applied when zero.
+	PatchTimeout time.Duration
+	// MaxPatchBytes skips the nodes whose patch would exceed the size, which
+	// the API server would reject. The size is not checked when zero.
+	MaxPatchBytes int
+	// APIReader reads objects bypassing the cache. The client is used when nil.
+	APIReader client.Reader
+	// LogAffectedPods logs the pods affected by NoExecute taint removals.
+	LogAffectedPods bool
+	// IgnoreNamespaces lists the namespaces whose pods are not counted as
+	// affected by NoExecute taint removals.
+	IgnoreNamespaces []string
+	// OnlyManageOwn restricts removal to taint keys recorded in the node's
+	// managed keys annotation.
+	OnlyManageOwn bool
+	// ConfirmNoExecute allows NoExecute taint removals. Without it they are
+	// only reported in the remover status.
+	ConfirmNoExecute bool
+	// ProtectNoExecuteWithPods keeps the NoExecute taints of nodes hosting
+	// pods that do not tolerate them.
+	ProtectNoExecuteWithPods bool
+	// MaxHeartbeatStaleness skips the nodes whose Ready condition was last
+	// reported longer ago. No node is skipped when zero.
+	MaxHeartbeatStaleness time.Duration
+	// RespectPDB keeps the NoExecute taints of nodes hosting pods whose
+	// PodDisruptionBudget allows no more disruptions.
+	RespectPDB bool
+	// AllowedTaintKeys restricts removal to the listed taint keys whatever
+	// the removers specify. Any key may be removed when empty.
+	AllowedTaintKeys []string
+	// CaseInsensitiveKeys matches the listed taint keys to the node taint
+	// keys ignoring case.
+	CaseInsensitiveKeys bool
+	// MaxPreviewNodes caps the nodes previewed in the status of an
+	// ObserveOnly remover. defaultMaxPreviewNodes is used when zero.
+	MaxPreviewNodes int
+	// RemoverSelector restricts the TaintRemovers processed to the ones whose
+	// labels match. All removers are processed when nil.
+	RemoverSelector labels.Selector
+	// Recorder emits events on the TaintRemovers. No event is emitted when nil.
+	Recorder record.EventRecorder
+	// TaintsFromEnv also removes the taints listed in TaintsEnvVar, which
+	// allows running without the TaintRemover CRD.
+	TaintsFromEnv bool
+	// DecodeTaintValues URL-decodes the values of the taints listed in
+	// TaintsEnvVar.
+	DecodeTaintValues bool
+	// RequireConfirmation ignores the TaintRemovers not annotated with
+	// ConfirmedAnnotation, so that a new remover only acts once blessed.
+	RequireConfirmation bool
+	// WarnUnmatchedTaints reports the taints listed by the removers that
+	// match no node after each full sweep.
+	WarnUnmatchedTaints bool
+	// DisableNodeWatch does not watch the nodes, so that taints are only
+	// removed by the sweeps on TaintRemover changes and periodic resyncs.
+	DisableNodeWatch bool
+	// ReconcileJitter adds a random delay of up to the duration to the
+	// requeue of each reconcile. No jitter is added when zero.
+	ReconcileJitter time.Duration
+	// MachineOwnerAnnotationKey is the node annotation matched against the
+	// MachineOwner of the removers. defaultMachineOwnerAnnotationKey is used
+	// when empty.
+	MachineOwnerAnnotationKey string
+	// ContestThreshold stops removing a taint for a backoff once it was
+	// removed that many times in a row, each within ContestTTL of the last
+	// one, as another controller keeps adding it back. Taints are never
+	// contested when zero.
+	ContestThreshold int
+	// ContestTTL is how soon a taint must be added back after its removal
+	// for the removal to count toward ContestThreshold. It is also the first
+	// backoff, which doubles on each further contested removal.
+	ContestTTL time.Duration
+	// NodeGroupAnnotationKey is the node annotation matched against the
+	// NodeGroup of the removers. defaultNodeGroupAnnotationKey is used when
+	// empty.
+	NodeGroupAnnotationKey string
+	// EmptyResultRequeue sweeps again after the duration when TaintRemovers
+	// exist but no node is tainted, in case node events are missed. No
+	// sweep is requeued when zero.
+	EmptyResultRequeue time.Duration
+	// NodeCooldown keeps a patched node from being patched again until the
+	// duration has elapsed, e.g. while it flaps. Nodes are not cooled down
+	// when zero.
+	NodeCooldown time.Duration
+	// RemoverDeletionEvents emits an event when a TaintRemover is deleted,
+	// listing the taints that are no longer removed. The deletion is only
+	// logged without it.
+	RemoverDeletionEvents bool
+	// PodName identifies the instance in the last actor annotation stamped
+	// on the TaintRemovers after each successful sweep.
+	PodName string
+	// Elected is closed once the instance is elected leader. Sweeps are
+	// skipped until then, so that a standby replica never removes taints.
+	// Sweeps always run when nil.
+	Elected <-chan struct{}
+	// OnReconcileComplete is called with the result at the end of each
+	// reconcile, so that tests can synchronize without polling.
+	OnReconcileComplete func(RemovalResult)
+
+	cacheSynced        atomic.Bool
+	nodeLabelsIndexed  bool
+	crdMissing         atomic.Bool
+	workloadPending    atomic.Bool
+	maintenancePending atomic.Bool
+	pdbPending         atomic.Bool
+	capPending         atomic.Bool
+	capRetry           atomic.Bool
+	caps               nodeCaps
+	createRetries      atomic.Int32
+	delays             removalDelays
+	cooldowns          nodeCooldowns
+	deleted            deletedRemovers
+	boots              bootIDs
+	cordoned           cordonedNodes
+	contests           contestedTaints
+	now                func() time.Time
+	randInt64N         func(int64) int64
+	trigger            chan event.GenericEvent
+	triggerOnce        sync.Once
+	patched            *utilcache.LRUExpireCache
+	patchedOnce        sync.Once
+	status             statusTracker
 }
 
 // nodePatchSpec represents a node object and its patch.
@@ -58,72 +213,250 @@ type nodePatchSpec struct {
 }
 
 // nodeSpecPatch defines the specification for patching a node's taints.
+// Unschedulable is only patched when the node is uncordoned.
 type nodeSpecPatch struct {
-	Taints []corev1.Taint `json:"taints"`
+	Taints        []corev1.Taint `json:"taints"`
+	Unschedulable *bool          `json:"unschedulable,omitempty"`
+}
+
+// nodeMetadataPatch defines the metadata for patching a node's annotations.
+type nodeMetadataPatch struct {
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
-// nodePatch represents a patch for a node object
+// nodePatch represents a patch for a node object, applied as a strategic
+// merge patch: the annotations are merged key by key, leaving the others
+// alone, while the taint list, which has no merge strategy, is replaced.
 type nodePatch struct {
-	Spec nodeSpecPatch `json:"spec"`
+	Metadata *nodeMetadataPatch `json:"metadata,omitempty"`
+	Spec     nodeSpecPatch      `json:"spec"`
 }
 
 //+kubebuilder:rbac:groups=nodes.peppy-ratio.dev,resources=taintremovers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=nodes.peppy-ratio.dev,resources=taintremovers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=nodes.peppy-ratio.dev,resources=taintremovers/finalizers,verbs=update
 //+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the TaintRemover object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.16.0/pkg/reconcile
+// Reconcile sweeps all nodes, removing the taints listed by the
+// TaintRemovers, and requeues when delayed or limited removals remain.
+// It is idempotent: nodes whose taints need no removal are never patched, so
+// reconciling again right after a sweep patches nothing.
 func (r *TaintRemoverReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	ctx = withCorrelation(ctx)
+	res, err := r.sweep(ctx)
+	res.RequeueAfter = r.jittered(res.RequeueAfter)
+	return res, err
+}
+
+// sweep removes the listed taints from all nodes and returns when the next
+// sweep is needed.
+func (r *TaintRemoverReconciler) sweep(ctx context.Context) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
+	var result RemovalResult
+	if r.OnReconcileComplete != nil {
+		defer func() { r.OnReconcileComplete(result) }()
+	}
 
-	taints, err := getAllRemoveTaints(ctx, r.Client)
+	if !r.elected() {
+		logger.Info("Standing by, not elected leader yet")
+		return ctrl.Result{}, nil
+	}
+	if !r.waitForCacheSync(ctx) {
+		logger.Info("Cache is not synced yet")
+		return ctrl.Result{Requeue: true}, nil
+	}
+	r.reportDeletedRemovers(ctx)
+
+	taints, err := getAllRemoveTaints(ctx, r.Client, r.Recorder, r.targetSource(), r.removerListOptions()...)
+	if r.checkMissingCRD(ctx, err) {
+		return ctrl.Result{RequeueAfter: missingCRDRequeue}, nil
+	}
 	if err != nil {
 		logger.Error(err, "Failed to get config")
 	}
 	if len(taints) < 1 {
+		r.status.recordSweep(r.currentTime(), 0, 0, err)
+		if err == nil && r.takeCreateRetry() {
+			logger.Info("TaintRemovers are not cached yet, retrying")
+			return ctrl.Result{RequeueAfter: createRetryDelay}, nil
+		}
 		return reconcile.Result{}, nil
 	}
+	r.createRetries.Store(0)
 	logger.Info("Got CRD targets", "taints", taints)
 
-	nodes, err := getTaintedNodes(ctx, r.Client)
+	nodes, err := r.getTargetNodes(ctx, taints)
 	if err != nil {
 		logger.Error(err, "Failed to get nodes")
+	} else {
+		r.warnUnmatchedTaints(ctx, nodes, taints)
+		r.delays.retain(nodes)
 	}
 	if len(nodes) < 1 {
+		r.status.recordSweep(r.currentTime(), 0, 0, err)
+		if err == nil && !r.cacheSyncedNow() {
+			// An unsynced cache may just not have the nodes yet.
+			logger.Info("No tainted nodes while the cache is not synced yet, retrying")
+			return reconcile.Result{RequeueAfter: cacheSyncRequeue}, nil
+		}
+		if err == nil {
+			r.stampLastActor(ctx)
+			return reconcile.Result{RequeueAfter: r.EmptyResultRequeue}, nil
+		}
 		return reconcile.Result{}, nil
 	}
 	logger.Info("Got nodes", "tainted nodes", len(nodes))
-	removed, err := removeTaints(ctx, r.Client, nodes, taints)
+	r.startCapWindow()
+	result, err = r.removeTaints(ctx, nodes, taints)
+	logger.Info("removed taints", result.keysAndValues()...)
+	r.status.recordSweep(r.currentTime(), len(nodes), result.TaintsRemoved, err)
+
+	var partial *PartialRemovalError
+	if goerrors.As(err, &partial) {
+		// The sweep made progress, so retry the failed nodes without backing off.
+		logger.Error(err, "Failed to remove taints from some nodes",
+			"succeeded", partial.Succeeded(), "failed", partial.Failed())
+		requeue := partialRemovalRequeue
+		if next := r.nextRequeue(); next > 0 {
+			requeue = min(requeue, next)
+		}
+		return ctrl.Result{RequeueAfter: requeue}, nil
+	}
 	if err != nil {
 		logger.Error(err, "Failed to remove taints")
+	} else {
+		r.stampLastActor(ctx)
+	}
+	return ctrl.Result{RequeueAfter: r.nextRequeue()}, err
+}
+
+// nextRequeue returns the time until the next sweep is needed for the
+// delayed, cooled down, gated or capped removals. Zero means no sweep is needed.
+func (r *TaintRemoverReconciler) nextRequeue() time.Duration {
+	next := r.delays.next(r.currentTime())
+	if cooled := r.cooldowns.next(r.currentTime()); cooled > 0 && (next <= 0 || cooled < next) {
+		next = cooled
+	}
+	if contested := r.contests.next(r.currentTime(), r.ContestTTL, r.ContestThreshold); contested > 0 &&
+		(next <= 0 || contested < next) {
+		next = contested
+	}
+	gated := r.workloadPending.Load() || r.maintenancePending.Load() || r.pdbPending.Load() || r.capPending.Load()
+	if gated && (next <= 0 || next > gatedRequeue) {
+		next = gatedRequeue
+	}
+	return next
+}
+
+// RunOnce runs a single sweep over all nodes without the manager's event loop.
+// The returned error is a PartialRemovalError when only some nodes failed.
+func (r *TaintRemoverReconciler) RunOnce(ctx context.Context) error {
+	taints, err := getAllRemoveTaints(ctx, r.Client, r.Recorder, r.targetSource(), r.removerListOptions()...)
+	if err != nil || len(taints) < 1 {
+		return err
+	}
+	nodes, err := r.getTargetNodes(ctx, taints)
+	if err != nil {
+		return err
+	}
+	r.warnUnmatchedTaints(ctx, nodes, taints)
+	if len(nodes) < 1 {
+		return nil
+	}
+	r.startCapWindow()
+	result, err := r.removeTaints(ctx, nodes, taints)
+	log.FromContext(ctx).Info("removed taints", result.keysAndValues()...)
+	r.status.recordSweep(r.currentTime(), len(nodes), result.TaintsRemoved, err)
+	return err
+}
+
+// currentTime returns the current time of the reconciler's clock.
+func (r *TaintRemoverReconciler) currentTime() time.Time {
+	if r.now != nil {
+		return r.now()
 	}
-	logger.Info("removed taints", "removed", removed)
+	return time.Now()
+}
 
-	return ctrl.Result{}, err
+// waitForCacheSync waits for the cache to be synced before the first sweep.
+// It returns false if the wait was aborted before the cache got synced, and
+// true once CacheSyncTimeout has passed even though it is not synced yet.
+func (r *TaintRemoverReconciler) waitForCacheSync(ctx context.Context) bool {
+	if r.Cache == nil || r.cacheSynced.Load() {
+		return true
+	}
+	waitCtx := ctx
+	if r.CacheSyncTimeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, r.CacheSyncTimeout)
+		defer cancel()
+	}
+	if !r.Cache.WaitForCacheSync(waitCtx) {
+		if ctx.Err() != nil {
+			return false
+		}
+		log.FromContext(ctx).Info("Cache is not synced in time, sweeping anyway",
+			"timeout", r.CacheSyncTimeout)
+		return true
+	}
+	r.cacheSynced.Store(true)
+	return true
+}
+
+// cacheSyncedNow reports whether the cache is synced without waiting for it.
+func (r *TaintRemoverReconciler) cacheSyncedNow() bool {
+	if r.Cache == nil || r.cacheSynced.Load() {
+		return true
+	}
+	// WaitForCacheSync checks once before giving up on a done context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if !r.Cache.WaitForCacheSync(ctx) {
+		return false
+	}
+	r.cacheSynced.Store(true)
+	return true
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *TaintRemoverReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&nodesv1alpha1.TaintRemover{}).
-		Watches(&corev1.Node{}, &nodeHandler{r: r},
-			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
-		Complete(r)
+	if err := r.indexNodeLabels(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&nodesv1alpha1.TaintRemover{}, builder.WithPredicates(r.removerDeletedPredicate()))
+	r.addWatches(b)
+	return b.Complete(r)
+}
+
+// watchBuilder is the part of the controller builder registering watches.
+type watchBuilder interface {
+	Watches(object client.Object, eventHandler handler.EventHandler, opts ...builder.WatchesOption) *builder.Builder
+	WatchesRawSource(src source.Source) *builder.Builder
+}
+
+// addWatches registers the watches other than the TaintRemovers. The node
+// watch is left out with DisableNodeWatch.
+func (r *TaintRemoverReconciler) addWatches(b watchBuilder) {
+	if !r.DisableNodeWatch {
+		b.Watches(&corev1.Node{}, &nodeHandler{r: r},
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}, r.nodeChangedPredicate()))
+	}
+	b.WatchesRawSource(r.triggerSource())
 }
 
 // applyTaintRemoveOnNode applies the removed taints on the new or updated Node.
-func applyTaintRemoveOnNode(ctx context.Context, c client.Client, node client.Object) error {
+func (r *TaintRemoverReconciler) applyTaintRemoveOnNode(ctx context.Context, node client.Object) error {
+	ctx = withCorrelation(ctx)
 	logger := log.FromContext(ctx)
 	logger.Info("applyTaintRemoveOnNode starting", "node", node.GetName(), "resver", node.GetResourceVersion())
 
+	if n, ok := node.(*corev1.Node); ok {
+		// An uncordoned node may have no taints left to be swept.
+		r.cordoned.observe(n)
+	}
+	c := r.Client
 	found, err := getNodeAndCheckTaints(ctx, c, node)
 	if err != nil || found == nil {
 		logger.V(2).Info("node not found or no taints", "node", node.GetName())
@@ -131,19 +464,26 @@ func applyTaintRemoveOnNode(ctx context.Context, c client.Client, node client.Ob
 	}
 
 	nodes := []*corev1.Node{found.DeepCopy()}
-	taints, err := getAllRemoveTaints(ctx, c)
+	taints, err := getAllRemoveTaints(ctx, c, r.Recorder, r.targetSource(), r.removerListOptions()...)
+	if r.checkMissingCRD(ctx, err) {
+		return nil
+	}
 	if err != nil {
 		logger.Error(err, "failed to get taints")
 		return err
 	}
 	logger.Info("applyTaintRemoveOnNode", "node taints", len(found.Spec.Taints), "target taints", len(taints))
 
-	removed, err := removeTaints(ctx, c, nodes, taints)
+	result, err := r.removeTaints(ctx, nodes, taints)
+	r.status.addRemoved(result.TaintsRemoved)
+	if r.capPending.Load() {
+		r.retryCappedLater()
+	}
 	if err != nil {
 		logger.Error(err, "failed to remove taints")
 		return err
 	}
-	logger.Info("removed taints", "removed", removed)
+	logger.Info("removed taints", result.keysAndValues()...)
 	return nil
 }
 
@@ -172,35 +512,143 @@ func getNodeAndCheckTaints(ctx context.Context, c client.Client, node client.Obj
 	return found, nil
 }
 
+// targetSource tells where getAllRemoveTaints takes the taints to remove
+// from: the TaintRemovers, confirmed or not, and the environment.
+type targetSource struct {
+	env                 envSource
+	requireConfirmation bool
+}
+
+// targetSource returns the source of the taints removed by the reconciler.
+func (r *TaintRemoverReconciler) targetSource() targetSource {
+	return targetSource{env: r.envSource(), requireConfirmation: r.RequireConfirmation}
+}
+
 // getAllRemoveTaints retrieves the list of taints from the TaintRemover objects in the cluster.
-func getAllRemoveTaints(ctx context.Context, c client.Client) ([]*corev1.Taint, error) {
+// Invalid taints are skipped with a Warning event on their remover when the
+// recorder is not nil, and so are unconfirmed removers when confirmation is
+// required.
+func getAllRemoveTaints(ctx context.Context, c client.Client, recorder record.EventRecorder, src targetSource,
+	opts ...client.ListOption) ([]*removeTarget, error) {
 	logger := log.FromContext(ctx)
+	env := src.env
 
 	removers := &nodesv1alpha1.TaintRemoverList{}
-	err := c.List(ctx, removers)
+	err := c.List(ctx, removers, opts...)
 	if err != nil {
-		logger.Error(err, "Failed to get Remover")
-		return nil, err
+		checkForbidden(ctx, err, "list", "taintremovers")
+		if !isMissingCRD(err) {
+			logger.Error(err, "Failed to get Remover")
+		}
+		// Without the CRD, the taints from the environment are removed alone.
+		if !env.enabled || !isMissingCRD(err) {
+			return nil, err
+		}
 	}
-	if len(removers.Items) < 1 {
+	if len(removers.Items) < 1 && !env.enabled {
 		return nil, nil
 	}
 
-	var taints []corev1.Taint
+	// Removers with a higher priority come first so that their targets win
+	// over the duplicates of lower priority removers.
+	slices.SortStableFunc(removers.Items, func(a, b nodesv1alpha1.TaintRemover) int {
+		return cmp.Compare(b.Spec.Priority, a.Spec.Priority)
+	})
+	var taints []removeTarget
+	seen := targetSet{}
 
 	for _, v := range removers.Items {
-		for _, t := range v.Spec.Taints {
-			if tutil.TaintExists(taints, &t) {
+		if src.requireConfirmation && !removerConfirmed(&v) {
+			logger.V(1).Info("Unconfirmed remover, skipping", "remover", v.Name)
+			if recorder != nil {
+				recorder.Eventf(&v, corev1.EventTypeWarning, "Unconfirmed",
+					"Skipping the remover until it is annotated with %s: \"true\"", nodesv1alpha1.ConfirmedAnnotation)
+			}
+			continue
+		}
+		targets, err := newRemoveTargets(&v)
+		if err != nil {
+			logger.Error(err, "Invalid remover, skipping", "remover", v.Name)
+			continue
+		}
+		mode, err := parseMatchMode(v.Annotations[nodesv1alpha1.MatchModeAnnotation])
+		if err != nil {
+			logger.Error(err, "Invalid match mode, matching exactly", "remover", v.Name)
+			if recorder != nil {
+				recorder.Eventf(&v, corev1.EventTypeWarning, "InvalidMatchMode",
+					"Matching taints exactly: %v", err)
+			}
+		}
+		effectOnly := v.Annotations[nodesv1alpha1.EffectOnlyAnnotation] == "true"
+		for _, target := range targets {
+			target.setMatchMode(mode)
+			target.setEffectOnly(effectOnly)
+			if err := target.validate(); err != nil {
+				if target.MatchExpression != "" {
+					logger.Error(err, "Invalid match expression, skipping", "remover", v.Name,
+						"expression", target.MatchExpression)
+					if recorder != nil {
+						recorder.Eventf(&v, corev1.EventTypeWarning, "InvalidMatchExpression",
+							"Skipping invalid match expression %q: %v", target.MatchExpression, err)
+					}
+					continue
+				}
+				logger.Error(err, "Invalid taint, skipping", "remover", v.Name, "taint", target.Taint.ToString())
+				if recorder != nil {
+					recorder.Eventf(&v, corev1.EventTypeWarning, "InvalidTaint",
+						"Skipping invalid taint %s: %v", target.Taint.ToString(), err)
+				}
 				continue
 			}
-			taints = append(taints, t)
+			if !seen.add(&target) {
+				continue
+			}
+			taints = append(taints, target)
 		}
 	}
+	if env.enabled {
+		targets, err := envTargets(env)
+		if err != nil {
+			logger.Error(err, "Invalid taints, skipping", "env", TaintsEnvVar)
+		}
+		for _, target := range targets {
+			if err := target.validate(); err != nil {
+				logger.Error(err, "Invalid taint, skipping", "env", TaintsEnvVar, "taint", target.Taint.ToString())
+				continue
+			}
+			if seen.add(&target) {
+				taints = append(taints, target)
+			}
+		}
+	}
+	slices.SortStableFunc(taints, compareTargets)
 
 	return ConvertToPointerArray(taints), nil
 }
 
-// ConvertToPointerArray converts a slice of type T to a slice of pointers to T
+// removerListOptions returns the options to list the TaintRemovers processed
+// by the reconciler.
+func (r *TaintRemoverReconciler) removerListOptions() []client.ListOption {
+	if r.RemoverSelector == nil {
+		return nil
+	}
+	return []client.ListOption{client.MatchingLabelsSelector{Selector: r.RemoverSelector}}
+}
+
+// liveReader returns the reader used to look up the objects the filters
+// check, such as pods, PodDisruptionBudgets, Deployments and ConfigMaps. The
+// API reader is preferred so that they are read fresh and not cached
+// cluster-wide.
+func (r *TaintRemoverReconciler) liveReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// ConvertToPointerArray converts a slice of type T to a slice of pointers to T.
+// Each pointer refers to a copy of the element, so none of them is nil even
+// when the elements are nil pointers themselves.
 func ConvertToPointerArray[T any](arr []T) []*T {
 	result := make([]*T, len(arr))
 	for i, obj := range arr {
@@ -221,6 +669,7 @@ func getTaintedNodes(ctx context.Context, c client.Client) ([]*corev1.Node, erro
 	list := &corev1.NodeList{}
 	err := c.List(ctx, list)
 	if err != nil {
+		checkForbidden(ctx, err, "list", "nodes")
 		return nil, err
 	}
 
@@ -233,60 +682,222 @@ func getTaintedNodes(ctx context.Context, c client.Client) ([]*corev1.Node, erro
 	return nodes, err
 }
 
-// removeTaints removes all taints from target nodes
-func removeTaints(ctx context.Context, c client.Client, nodes []*corev1.Node, taints []*corev1.Taint) (int, error) {
+// removeTaints removes all taints from target nodes.
+// A node patch that fails does not abort the sweep; the errors of all the
+// failed nodes are returned together after the remaining nodes are processed
+// so that they are retried. A PartialRemovalError is returned when some nodes
+// were patched.
+func (r *TaintRemoverReconciler) removeTaints(ctx context.Context, nodes []*corev1.Node, taints []*removeTarget) (RemovalResult, error) {
 	logger := log.FromContext(ctx)
-	removed := 0
+	var result RemovalResult
+	var errs []error
 
-	patches := makePatches(nodes, taints)
-	for _, n := range patches {
-		err := patchNode(ctx, c, n.node, *n.patch)
+	r.contests.prune(r.currentTime(), r.ContestTTL, r.ContestThreshold)
+	r.cooldowns.prune(r.currentTime())
+	rebooted := make(map[string]bool)
+	for _, n := range nodes {
+		r.delays.prune(n)
+		r.cordoned.observe(n)
+		if r.boots.observe(n) {
+			rebooted[n.Name] = true
+		}
+	}
+	noExecute := noExecuteRemovals{}
+	removals := taintRemovals{}
+	previews := previewRemovals{}
+	patches := makePatches(nodes, taints, r.currentTime(), r.keyMatcher(),
+		r.allowedKey, r.inNodeGroup, r.ownedByMachineOwner, r.heartbeatFresh, r.cordonFilter(ctx), r.uncontested,
+		r.delayElapsed, r.ownedTaint, r.noExecuteFilter(noExecute), r.protectNoExecuteFilter(ctx), r.pdbFilter(ctx), r.workloadFilter(ctx),
+		r.maintenanceFilter(ctx), observeFilter(previews), r.maxNodesFilter(ctx), recordRemovals(removals))
+	result.NodesSkipped = len(nodes) - len(patches)
+	patched := make(map[string]nodesv1alpha1.TaintOutcome, len(patches))
+	defer func() {
+		// Failing to record the sweep does not fail it, as the nodes are patched.
+		report := &sweepReport{nodes: nodes, previews: previews, noExecute: noExecute, removals: removals, patched: patched}
+		if err := r.reportRemoverStatuses(ctx, report); err != nil {
+			logger.Error(err, "Failed to report remover statuses")
+		}
+		if err := r.reportRemovalSummaries(ctx, removals, patched); err != nil {
+			logger.Error(err, "Failed to report removal summaries")
+		}
+	}()
+	if !r.ConfirmNoExecute && len(noExecute) > 0 {
+		logger.Info("NoExecute removals skipped, confirm with --confirm-noexecute", "removals", noExecute)
+	}
+	defer func() {
+		// The nodes skipped or failed do not count toward MaxNodes.
+		for _, n := range patches {
+			if patched[n.node.Name] == nodesv1alpha1.TaintOutcomeRemoved {
+				r.caps.commit(n.node.Name)
+			} else {
+				r.caps.release(n.node.Name)
+			}
+		}
+	}()
+	for _, n := range fairOrder(patches, taints, removals) {
+		key := patchKey(n.node)
+		if r.cooldowns.skip(n.node.Name, r.currentTime()) {
+			logger.V(1).Info("Skipping node in cooldown", "node", n.node.Name)
+			result.NodesSkipped++
+			continue
+		}
+		if rebooted[n.node.Name] {
+			logger.Info("Node rebooted, removing taints again", "node", n.node.Name,
+				"bootID", n.node.Status.NodeInfo.BootID)
+		} else if r.recentlyPatched(key) {
+			logger.V(1).Info("Skipping recently patched node", "node", n.node.Name, "resver", n.node.ResourceVersion)
+			result.NodesSkipped++
+			continue
+		}
+		if r.LogAffectedPods {
+			r.logAffectedPods(ctx, n.node, n.patch.Spec.Taints)
+		}
+		removed := len(n.node.Spec.Taints) - len(n.patch.Spec.Taints)
+		_, removedTaints := tutil.TaintSetDiff(n.patch.Spec.Taints, n.node.Spec.Taints)
+		n.patch.Metadata.Annotations[nodesv1alpha1.HistoryAnnotation] =
+			appendHistory(n.node, removedTaints, r.currentTime())
+		err := r.patchNode(ctx, n.node, *n.patch)
+		if goerrors.Is(err, errPatchTooLarge) {
+			r.skipOversizedPatch(ctx, n.node, err)
+			result.NodesSkipped++
+			continue
+		}
 		if err != nil {
-			logger.Error(err, "Failed to patch node")
-			return removed, err
+			result.Failures = append(result.Failures, NodeFailure{Node: n.node.Name, Err: err})
+			patched[n.node.Name] = nodesv1alpha1.TaintOutcomeFailed
+			errs = append(errs, fmt.Errorf("node %s: %w", n.node.Name, err))
+			if goerrors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				logger.Error(err, "Timed out patching node", "node", n.node.Name)
+				continue
+			}
+			checkForbidden(ctx, err, "patch", "nodes")
+			logger.Error(err, "Failed to patch node", "node", n.node.Name)
+			if ctx.Err() != nil {
+				// The remaining patches would fail the same way.
+				break
+			}
+			continue
+		}
+		r.markPatched(key)
+		if r.NodeCooldown > 0 {
+			r.cooldowns.start(n.node.Name, r.currentTime().Add(r.NodeCooldown))
 		}
-		removed++
+		r.recordContests(n.node, removedTaints)
+		patched[n.node.Name] = nodesv1alpha1.TaintOutcomeRemoved
+		countRemovedByRole(n.node, removed)
+		countRemovedByZone(n.node, removed)
+		if removed > 0 {
+			lastRemoval.record()
+		}
+		result.NodesPatched++
+		result.TaintsRemoved += removed
 	}
-	return removed, nil
+	return result, removalError(&result, goerrors.Join(errs...))
 }
 
-// makePatches creates patch objects for nodes that need taint updates
-func makePatches(nodes []*corev1.Node, taints []*corev1.Taint) []nodePatchSpec {
+// removalError returns a PartialRemovalError when err is set and some nodes
+// were patched, and err otherwise.
+func removalError(result *RemovalResult, err error) error {
+	if err == nil {
+		return nil
+	}
+	if partial := newPartialRemovalError(result); partial != nil {
+		return partial
+	}
+	return err
+}
+
+// keyMatcher returns the matcher of the listed taint keys.
+func (r *TaintRemoverReconciler) keyMatcher() keyMatcher {
+	if r.CaseInsensitiveKeys {
+		return strings.EqualFold
+	}
+	return exactKeyMatch
+}
+
+// makePatches creates patch objects for nodes that need taint updates.
+// Each patch also stamps the node with the last patch made at now, and
+// uncordons the node when a removed taint comes from an Uncordon target.
+func makePatches(nodes []*corev1.Node, taints []*removeTarget, now time.Time, keyMatch keyMatcher,
+	filters ...removalFilter) []nodePatchSpec {
 	var result []nodePatchSpec
 
 	for _, n := range nodes {
-		newTaints, needPatch := makeNewTaintsForNode(n, taints)
+		uncordon := false
+		markUncordon := func(_ *corev1.Node, target *removeTarget) bool {
+			uncordon = uncordon || target.Uncordon
+			return true
+		}
+		newTaints, needPatch := makeNewTaintsForNode(n, taints, keyMatch, append(slices.Clip(filters), markUncordon)...)
 		if !needPatch {
 			continue
 		}
-		patch := nodePatch{Spec: nodeSpecPatch{Taints: newTaints}}
+		_, removed := tutil.TaintSetDiff(newTaints, n.Spec.Taints)
+		patch := nodePatch{
+			Metadata: &nodeMetadataPatch{
+				Annotations: map[string]string{
+					nodesv1alpha1.ManagedKeysAnnotation: mergeManagedKeys(n, removed),
+					nodesv1alpha1.LastPatchAnnotation:   lastPatchValue(now),
+				},
+			},
+			Spec: nodeSpecPatch{Taints: newTaints},
+		}
+		if uncordon && n.Spec.Unschedulable {
+			schedulable := false
+			patch.Spec.Unschedulable = &schedulable
+		}
 		result = append(result, nodePatchSpec{node: n.DeepCopy(), patch: &patch})
 	}
 	return result
 }
 
 // makeNewTaintsForNode removes the specified taints from the target node.
+// Targets whose node selector does not select the node are ignored.
+// Taints whose key does not start with one of the target's sources, whose
+// target conditions are not cleared on the node, or which are rejected by
+// any of the filters are kept.
+// Listed taint keys are matched to the node taint keys by keyMatch.
 // It returns the updated list of taints after removing the specified taints,
 // as well as a boolean indicating whether any taints were removed.
-func makeNewTaintsForNode(target *corev1.Node, taints []*corev1.Taint) ([]corev1.Taint, bool) {
+func makeNewTaintsForNode(target *corev1.Node, taints []*removeTarget, keyMatch keyMatcher,
+	filters ...removalFilter) ([]corev1.Taint, bool) {
 	if target == nil {
 		return nil, false
 	}
-	nodeTaints := target.Spec.Taints
-	deleted := false
+	// The removed taints are collected into an index and dropped in one pass,
+	// rather than deleting each of them from the node taints in turn.
+	removed := tutil.TaintIndex{}
 	for _, taint := range taints {
-		if !tutil.TaintExists(nodeTaints, taint) {
+		if taint == nil || !taint.selects(target) {
 			continue
 		}
-		var taintDeleted bool
-		nodeTaints, taintDeleted = tutil.DeleteTaint(nodeTaints, taint)
-		deleted = deleted || taintDeleted
+		for _, candidate := range taint.candidates(target, keyMatch) {
+			if removed.Contains(&candidate.Taint) || candidate.effectExcluded() {
+				continue
+			}
+			if !candidate.sourceAllowed(candidate.Taint.Key) || !candidate.conditionsCleared(target) {
+				continue
+			}
+			if !allowedByFilters(target, candidate, filters) {
+				continue
+			}
+			removed.Add(&candidate.Taint)
+		}
+	}
+	if len(removed) == 0 {
+		return target.Spec.Taints, false
 	}
-	return nodeTaints, deleted
+	nodeTaints := make([]corev1.Taint, 0, len(target.Spec.Taints))
+	for i := range target.Spec.Taints {
+		if !removed.Contains(&target.Spec.Taints[i]) {
+			nodeTaints = append(nodeTaints, target.Spec.Taints[i])
+		}
+	}
+	return nodeTaints, true
 }
 
 // patchNode patches the specified node object with the given patch.
-func patchNode(ctx context.Context, c client.Client, node *corev1.Node, patch any) error {
+func (r *TaintRemoverReconciler) patchNode(ctx context.Context, node *corev1.Node, patch any) error {
 	logger := log.FromContext(ctx)
 
 	data, err := json.Marshal(patch)
@@ -294,9 +905,27 @@ func patchNode(ctx context.Context, c client.Client, node *corev1.Node, patch an
 		logger.Error(err, "Failed to marshal node patch")
 		return err
 	}
+	if r.MaxPatchBytes > 0 && len(data) > r.MaxPatchBytes {
+		return fmt.Errorf("%w: %d bytes exceed the limit of %d", errPatchTooLarge, len(data), r.MaxPatchBytes)
+	}
 	logger.Info("Apply node patch", "Patch", string(data))
 	raw := client.RawPatch(types.StrategicMergePatchType, data)
-	return c.Patch(ctx, node, raw)
+	if r.PatchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.PatchTimeout)
+		defer cancel()
+	}
+	return r.Client.Patch(ctx, node, raw)
+}
+
+// skipOversizedPatch reports that the patch of the node exceeded
+// MaxPatchBytes and was skipped.
+func (r *TaintRemoverReconciler) skipOversizedPatch(ctx context.Context, node *corev1.Node, err error) {
+	log.FromContext(ctx).Error(err, "Skipping node with an oversized patch", "node", node.Name)
+	oversizedPatches.Inc()
+	if r.Recorder != nil {
+		r.Recorder.Eventf(nodeReference(node), corev1.EventTypeWarning, "PatchTooLarge", "Skipping taint removal: %v", err)
+	}
 }
 
 // nodeHandler is a struct that implements the EventHandler interface.
@@ -304,18 +933,40 @@ type nodeHandler struct {
 	r *TaintRemoverReconciler
 }
 
-func (nh *nodeHandler) Create(ctx context.Context, evt event.CreateEvent, _ workqueue.RateLimitingInterface) {
-	_ = applyTaintRemoveOnNode(ctx, nh.r.Client, evt.Object)
+func (nh *nodeHandler) Create(ctx context.Context, evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	_ = nh.r.applyTaintRemoveOnNode(ctx, evt.Object)
+	// A node may join with its taints before the TaintRemovers are cached,
+	// which leaves nothing to remove. Sweep again until they are.
+	if node, ok := evt.Object.(*corev1.Node); ok && len(node.Spec.Taints) > 0 && nh.r.removersNotCached(ctx) {
+		nh.r.scheduleCreateRetries()
+		q.AddAfter(reconcile.Request{}, createRetryDelay)
+	}
+	nh.requeueDelayed(ctx, q)
+}
+
+func (nh *nodeHandler) Update(ctx context.Context, evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	_ = nh.r.applyTaintRemoveOnNode(ctx, evt.ObjectNew)
+	nh.requeueDelayed(ctx, q)
 }
 
-func (nh *nodeHandler) Update(ctx context.Context, evt event.UpdateEvent, _ workqueue.RateLimitingInterface) {
-	_ = applyTaintRemoveOnNode(ctx, nh.r.Client, evt.ObjectNew)
+// requeueDelayed schedules a sweep for when the earliest delayed or
+// gated taint may become removable.
+func (nh *nodeHandler) requeueDelayed(ctx context.Context, q workqueue.RateLimitingInterface) {
+	if next := nh.r.nextRequeue(); next > 0 {
+		q.AddAfter(reconcile.Request{}, next)
+	}
+	observeQueueDepth(ctx, q)
 }
 
-func (nh *nodeHandler) Delete(context.Context, event.DeleteEvent, workqueue.RateLimitingInterface) {
-	// No-op
+func (nh *nodeHandler) Delete(ctx context.Context, evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	if evt.Object != nil {
+		nh.r.boots.forget(evt.Object.GetName())
+		nh.r.cordoned.forget(evt.Object.GetName())
+		nh.r.delays.forget(evt.Object.GetName())
+	}
+	observeQueueDepth(ctx, q)
 }
 
-func (nh *nodeHandler) Generic(context.Context, event.GenericEvent, workqueue.RateLimitingInterface) {
-	// No-op
+func (nh *nodeHandler) Generic(ctx context.Context, _ event.GenericEvent, q workqueue.RateLimitingInterface) {
+	observeQueueDepth(ctx, q)
 }
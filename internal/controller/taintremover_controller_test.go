@@ -2,6 +2,8 @@ package controller
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -9,6 +11,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -120,6 +123,84 @@ var _ = Describe("TaintRemoverReconciler", func() {
 	})
 })
 
+var _ = Describe("Reconcile cache sync", func() {
+	It("should not sweep until the cache is synced", func() {
+		synced := make(chan struct{})
+		c := &countingClient{Client: newFakeClient()}
+		reconciler := &TaintRemoverReconciler{
+			Client: c,
+			Cache:  &fakeCache{synced: synced},
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		Consistently(done).ShouldNot(BeClosed())
+		Expect(c.lists.Load()).To(BeZero())
+
+		close(synced)
+		Eventually(done).Should(BeClosed())
+		Expect(c.lists.Load()).NotTo(BeZero())
+	})
+
+	It("should requeue when the wait is aborted", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		c := &countingClient{Client: newFakeClient()}
+		reconciler := &TaintRemoverReconciler{
+			Client: c,
+			Cache:  &fakeCache{synced: make(chan struct{})},
+		}
+
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeTrue())
+		Expect(c.lists.Load()).To(BeZero())
+	})
+
+	Context("when no node is tainted", func() {
+		var objs []client.Object
+
+		BeforeEach(func() {
+			objs = []client.Object{
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}},
+				&nodesv1alpha1.TaintRemover{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+					Spec: nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{
+						{Key: "foo", Effect: corev1.TaintEffectNoSchedule},
+					}},
+				},
+			}
+		})
+
+		reconcileWith := func(synced chan struct{}) ctrl.Result {
+			reconciler := &TaintRemoverReconciler{
+				Client:           newFakeClient(objs...),
+				Cache:            &fakeCache{synced: synced},
+				CacheSyncTimeout: 10 * time.Millisecond,
+			}
+			result, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			return result
+		}
+
+		It("should requeue shortly while the cache is not synced", func() {
+			Expect(reconcileWith(make(chan struct{}))).To(Equal(ctrl.Result{RequeueAfter: cacheSyncRequeue}))
+		})
+
+		It("should not requeue once the cache is synced", func() {
+			synced := make(chan struct{})
+			close(synced)
+			Expect(reconcileWith(synced)).To(Equal(ctrl.Result{}))
+		})
+	})
+})
+
 var _ = Describe("SetupWithManager", func() {
 	var (
 		ctx    context.Context
@@ -160,15 +241,17 @@ var _ = Describe("SetupWithManager", func() {
 
 var _ = Describe("internalMethods", func() {
 	var (
-		ctx    context.Context
-		client client.Client
-		tr     *v1alpha1.TaintRemover
-		node   *corev1.Node
+		ctx        context.Context
+		client     client.Client
+		reconciler *TaintRemoverReconciler
+		tr         *v1alpha1.TaintRemover
+		node       *corev1.Node
 	)
 
 	BeforeEach(func() {
 		ctx = context.TODO()
 		client = k8sClient
+		reconciler = &TaintRemoverReconciler{Client: client}
 		tr = nil
 		node = nil
 	})
@@ -188,7 +271,7 @@ var _ = Describe("internalMethods", func() {
 				// Create a TaintRemover object
 				node, tr = setupNodeAndRemover(fooBarTaint, fooBarTaint)
 
-				err := applyTaintRemoveOnNode(ctx, client, node)
+				err := reconciler.applyTaintRemoveOnNode(ctx, node)
 				Expect(err).NotTo(HaveOccurred())
 
 				// Verify that the taints have been removed from the node
@@ -205,7 +288,7 @@ var _ = Describe("internalMethods", func() {
 				// Create a TaintRemover object
 				node, tr = setupNodeAndRemover(fooBarTaint, emptyTait)
 
-				err := applyTaintRemoveOnNode(ctx, client, node)
+				err := reconciler.applyTaintRemoveOnNode(ctx, node)
 				Expect(err).NotTo(HaveOccurred())
 
 				// Verify that the taints have not been removed from the node
@@ -222,7 +305,7 @@ var _ = Describe("internalMethods", func() {
 				// Create a TaintRemover object
 				node = createNodeWithTaints(fooBarTaint)
 
-				err := applyTaintRemoveOnNode(ctx, client, node)
+				err := reconciler.applyTaintRemoveOnNode(ctx, node)
 				Expect(err).NotTo(HaveOccurred())
 
 				// Verify that the taints have not been removed from the node
@@ -236,6 +319,283 @@ var _ = Describe("internalMethods", func() {
 	})
 })
 
+var _ = Describe("removeTaints", func() {
+	Context("When a node patch times out", func() {
+		It("should continue with the next node and return the timeout", func() {
+			taint := corev1.Taint{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoSchedule}
+			slow := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "slow-node"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+			}
+			fast := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "fast-node"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+			}
+			c := &blockingPatchClient{Client: newFakeClient(slow, fast), block: slow.Name}
+			reconciler := &TaintRemoverReconciler{Client: c, PatchTimeout: 10 * time.Millisecond}
+
+			result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{slow, fast},
+				[]*removeTarget{{Taint: taint}})
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(result.NodesPatched).To(Equal(1))
+
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: fast.Name}, fast)).To(Succeed())
+			Expect(fast.Spec.Taints).To(BeEmpty())
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: slow.Name}, slow)).To(Succeed())
+			Expect(slow.Spec.Taints).To(HaveLen(1))
+		})
+	})
+
+	Context("When a node patch conflicts", func() {
+		It("should return the conflict and remove the taint on retry", func() {
+			taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+			}
+			tr := &nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+				Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{taint}},
+			}
+			c := newConflictOnceClient(node, tr)
+			reconciler := &TaintRemoverReconciler{Client: c}
+
+			_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{})
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(context.TODO(), types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+			Expect(node.Spec.Taints).To(BeEmpty())
+		})
+	})
+
+	Context("When nodes are patched, skipped and failed", func() {
+		It("should account for every node", func() {
+			foo := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+			bar := corev1.Taint{Key: "bar", Effect: corev1.TaintEffectNoSchedule}
+			other := corev1.Taint{Key: "other", Effect: corev1.TaintEffectNoSchedule}
+			patched := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "patched-node"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{foo, bar, other}},
+			}
+			skipped := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "skipped-node"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{other}},
+			}
+			failed := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "failed-node"},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{foo}},
+			}
+			c := &blockingPatchClient{Client: newFakeClient(patched, skipped, failed), block: failed.Name}
+			reconciler := &TaintRemoverReconciler{Client: c, PatchTimeout: 10 * time.Millisecond}
+
+			result, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{patched, skipped, failed},
+				[]*removeTarget{{Taint: foo}, {Taint: bar}})
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(result.NodesPatched).To(Equal(1))
+			Expect(result.TaintsRemoved).To(Equal(2))
+			Expect(result.NodesSkipped).To(Equal(1))
+			Expect(result.Failures).To(HaveLen(1))
+			Expect(result.Failures[0].Node).To(Equal(failed.Name))
+			Expect(result.Failures[0].Err).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+})
+
+var _ = Describe("makeNewTaintsForNode", func() {
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec: corev1.NodeSpec{
+				Taints: []corev1.Taint{
+					{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "node.kubernetes.io/not-ready", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+		}
+	})
+
+	Context("When the target has no sources", func() {
+		It("should remove the matching taint", func() {
+			targets := []*removeTarget{
+				{Taint: corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoSchedule}},
+			}
+			taints, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch)
+			Expect(deleted).To(BeTrue())
+			Expect(taints).To(HaveLen(1))
+			Expect(taints[0].Key).To(Equal("node.kubernetes.io/not-ready"))
+		})
+	})
+
+	Context("When the targets contain nil", func() {
+		It("should skip the nil targets", func() {
+			targets := []*removeTarget{
+				nil,
+				{Taint: corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoSchedule}},
+				nil,
+			}
+			var taints []corev1.Taint
+			var deleted bool
+			Expect(func() {
+				taints, deleted = makeNewTaintsForNode(node, targets, exactKeyMatch)
+			}).NotTo(Panic())
+			Expect(deleted).To(BeTrue())
+			Expect(taints).To(HaveLen(1))
+			Expect(taints[0].Key).To(Equal("node.kubernetes.io/not-ready"))
+		})
+	})
+
+	Context("When the taint key differs in case", func() {
+		var targets []*removeTarget
+
+		BeforeEach(func() {
+			targets = []*removeTarget{
+				{Taint: corev1.Taint{Key: "Cloud.Example.com/Spot", Effect: corev1.TaintEffectNoSchedule}},
+			}
+		})
+
+		It("should keep the taint with exact matching", func() {
+			taints, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch)
+			Expect(deleted).To(BeFalse())
+			Expect(taints).To(Equal(node.Spec.Taints))
+		})
+
+		It("should remove the taint with case-insensitive matching", func() {
+			taints, deleted := makeNewTaintsForNode(node, targets, strings.EqualFold)
+			Expect(deleted).To(BeTrue())
+			Expect(taints).To(HaveLen(1))
+			Expect(taints[0].Key).To(Equal("node.kubernetes.io/not-ready"))
+		})
+	})
+
+	Context("When the taint key starts with an allowed source", func() {
+		It("should remove the matching taint", func() {
+			targets := []*removeTarget{
+				{
+					Taint:   corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoSchedule},
+					Sources: []string{"autoscaler.example.com/", "cloud.example.com/"},
+				},
+			}
+			taints, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch)
+			Expect(deleted).To(BeTrue())
+			Expect(taints).To(HaveLen(1))
+		})
+	})
+
+	Context("When the taint key comes from a disallowed source", func() {
+		It("should preserve the taint", func() {
+			targets := []*removeTarget{
+				{
+					Taint:   corev1.Taint{Key: "node.kubernetes.io/not-ready", Effect: corev1.TaintEffectNoSchedule},
+					Sources: []string{"cloud.example.com/"},
+				},
+			}
+			taints, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch)
+			Expect(deleted).To(BeFalse())
+			Expect(taints).To(Equal(node.Spec.Taints))
+		})
+	})
+
+	Context("When the target excludes an effect", func() {
+		It("should only remove the taints with a non-excluded effect", func() {
+			node.Spec.Taints = append(node.Spec.Taints,
+				corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoExecute})
+			exclude := []corev1.TaintEffect{corev1.TaintEffectNoExecute}
+			targets := []*removeTarget{
+				{
+					Taint:          corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoSchedule},
+					ExcludeEffects: exclude,
+				},
+				{
+					Taint:          corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoExecute},
+					ExcludeEffects: exclude,
+				},
+			}
+			taints, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch)
+			Expect(deleted).To(BeTrue())
+			Expect(taints).To(ConsistOf(
+				corev1.Taint{Key: "node.kubernetes.io/not-ready", Effect: corev1.TaintEffectNoSchedule},
+				corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoExecute},
+			))
+		})
+
+		It("should preserve the excluded effects of a RemoveAll target", func() {
+			node.Spec.Taints = append(node.Spec.Taints,
+				corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoExecute})
+			targets := []*removeTarget{
+				{RemoveAll: true, ExcludeEffects: []corev1.TaintEffect{corev1.TaintEffectNoExecute}},
+			}
+			taints, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch)
+			Expect(deleted).To(BeTrue())
+			Expect(taints).To(ConsistOf(
+				corev1.Taint{Key: "node.kubernetes.io/not-ready", Effect: corev1.TaintEffectNoSchedule},
+				corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoExecute},
+			))
+		})
+	})
+
+	Context("When the target requires a node condition to be False", func() {
+		var targets []*removeTarget
+
+		BeforeEach(func() {
+			node.Spec.Taints = append(node.Spec.Taints,
+				corev1.Taint{Key: "node.kubernetes.io/disk-pressure", Effect: corev1.TaintEffectNoSchedule})
+			targets = []*removeTarget{
+				{
+					Taint:              corev1.Taint{Key: "node.kubernetes.io/disk-pressure", Effect: corev1.TaintEffectNoSchedule},
+					WhenConditionFalse: []corev1.NodeConditionType{corev1.NodeDiskPressure},
+				},
+			}
+		})
+
+		It("should preserve the taint while the condition is True", func() {
+			node.Status.Conditions = []corev1.NodeCondition{
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+			}
+			taints, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch)
+			Expect(deleted).To(BeFalse())
+			Expect(taints).To(HaveLen(3))
+		})
+
+		It("should remove the taint when the condition is False", func() {
+			node.Status.Conditions = []corev1.NodeCondition{
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+			}
+			taints, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch)
+			Expect(deleted).To(BeTrue())
+			Expect(taints).To(HaveLen(2))
+		})
+
+		It("should remove the taint when the condition is absent", func() {
+			taints, deleted := makeNewTaintsForNode(node, targets, exactKeyMatch)
+			Expect(deleted).To(BeTrue())
+			Expect(taints).To(HaveLen(2))
+		})
+	})
+})
+
+var _ = Describe("ConvertToPointerArray", func() {
+	It("should return pointers to copies of the elements", func() {
+		taints := []corev1.Taint{{Key: "foo"}, {Key: "bar"}}
+		pointers := ConvertToPointerArray(taints)
+		Expect(pointers).To(HaveLen(2))
+		Expect(*pointers[0]).To(Equal(taints[0]))
+		Expect(*pointers[1]).To(Equal(taints[1]))
+		pointers[0].Key = "changed"
+		Expect(taints[0].Key).To(Equal("foo"))
+	})
+
+	It("should not return nil pointers for nil elements", func() {
+		pointers := ConvertToPointerArray([]*corev1.Taint{nil, {Key: "foo"}})
+		Expect(pointers).To(HaveLen(2))
+		Expect(pointers[0]).NotTo(BeNil())
+		Expect(*pointers[0]).To(BeNil())
+	})
+})
+
 var fooBarTaint = []corev1.Taint{
 	{
 		Key:    "foo",
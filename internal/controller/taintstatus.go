@@ -0,0 +1,204 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// taintRemoval is the removal of a taint requested by a remover.
+type taintRemoval struct {
+	remover string
+	taint   corev1.Taint
+}
+
+// taintRemovals holds the taint removals of a sweep by node name.
+type taintRemovals map[string][]taintRemoval
+
+// recordRemovals returns a removal filter that records the taint removals
+// into removals. It allows every removal, so it must come last for only the
+// removals allowed by the other filters to be recorded.
+func recordRemovals(removals taintRemovals) removalFilter {
+	return func(node *corev1.Node, target *removeTarget) bool {
+		removals[node.Name] = append(removals[node.Name], taintRemoval{remover: target.remover, taint: target.Taint})
+		return true
+	}
+}
+
+// taintOutcome returns the outcome of the sweep for the taint listed by the
+// remover. It is Failed when a patch removing it failed, Removed when a patch
+// removing it succeeded and NotPresent otherwise.
+func (r *TaintRemoverReconciler) taintOutcome(remover string, taint *corev1.Taint, removals taintRemovals,
+	patched map[string]nodesv1alpha1.TaintOutcome) nodesv1alpha1.TaintOutcome {
+	keyMatch := r.keyMatcher()
+	outcome := nodesv1alpha1.TaintOutcomeNotPresent
+	for node, entries := range removals {
+		result, ok := patched[node]
+		if !ok {
+			continue
+		}
+		for _, e := range entries {
+			if e.remover != remover || e.taint.Effect != taint.Effect || !keyMatch(e.taint.Key, taint.Key) {
+				continue
+			}
+			if result == nodesv1alpha1.TaintOutcomeFailed {
+				return result
+			}
+			outcome = result
+		}
+	}
+	return outcome
+}
+
+// removalCount returns the number of nodes the taint listed by the remover
+// was removed from in the sweep.
+func (r *TaintRemoverReconciler) removalCount(remover string, taint *corev1.Taint, removals taintRemovals,
+	patched map[string]nodesv1alpha1.TaintOutcome) int32 {
+	keyMatch := r.keyMatcher()
+	var count int32
+	for node, entries := range removals {
+		if patched[node] != nodesv1alpha1.TaintOutcomeRemoved {
+			continue
+		}
+		for _, e := range entries {
+			if e.remover == remover && e.taint.Effect == taint.Effect && keyMatch(e.taint.Key, taint.Key) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// removalCountKey returns the key of the taint in RemovalCounts.
+func removalCountKey(taint *corev1.Taint) string {
+	return taint.Key + ":" + string(taint.Effect)
+}
+
+// addRemovalCounts returns the RemovalCounts of the remover with the
+// removals of the sweep for the listed taints added.
+func (r *TaintRemoverReconciler) addRemovalCounts(remover *nodesv1alpha1.TaintRemover, removals taintRemovals,
+	patched map[string]nodesv1alpha1.TaintOutcome) map[string]int32 {
+	counts := maps.Clone(remover.Status.RemovalCounts)
+	for _, t := range remover.Spec.Taints {
+		n := r.removalCount(remover.Name, &t, removals, patched)
+		if n < 1 {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[string]int32)
+		}
+		counts[removalCountKey(&t)] += n
+	}
+	return counts
+}
+
+// taintStatuses returns the outcome of the sweep for the taints listed by
+// the remover. The transition time of a taint is kept while its outcome does
+// not change. A taint the sweep found on no node keeps its previous outcome,
+// as the sweep may have covered a single node or followed the removal of the
+// taint; it is NotPresent only until it gets an outcome.
+func (r *TaintRemoverReconciler) taintStatuses(remover *nodesv1alpha1.TaintRemover, removals taintRemovals,
+	patched map[string]nodesv1alpha1.TaintOutcome, now metav1.Time) []nodesv1alpha1.TaintStatus {
+	var entries []nodesv1alpha1.TaintStatus
+	for _, t := range remover.Spec.Taints {
+		entry := nodesv1alpha1.TaintStatus{
+			Key:                t.Key,
+			Effect:             t.Effect,
+			Outcome:            r.taintOutcome(remover.Name, &t, removals, patched),
+			LastTransitionTime: now,
+		}
+		for _, prev := range remover.Status.TaintStatuses {
+			if prev.Key != entry.Key || prev.Effect != entry.Effect {
+				continue
+			}
+			if entry.Outcome == nodesv1alpha1.TaintOutcomeNotPresent {
+				entry.Outcome = prev.Outcome
+			}
+			if prev.Outcome == entry.Outcome {
+				entry.LastTransitionTime = prev.LastTransitionTime
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// sweepReport holds what a sweep records in the status of the removers.
+type sweepReport struct {
+	nodes     []*corev1.Node
+	previews  previewRemovals
+	noExecute noExecuteRemovals
+	removals  taintRemovals
+	patched   map[string]nodesv1alpha1.TaintOutcome
+}
+
+// reportRemoverStatuses records the sweep in the status of the removers:
+// the previews and the NoExecute removals found on the swept nodes, the
+// outcome for the listed taints and their removal counts. The status of each
+// remover is written once, only when it changed. A failed write does not
+// prevent writing the status of the other removers.
+func (r *TaintRemoverReconciler) reportRemoverStatuses(ctx context.Context, report *sweepReport) error {
+	removers := &nodesv1alpha1.TaintRemoverList{}
+	if err := r.List(ctx, removers, r.removerListOptions()...); err != nil {
+		if isMissingCRD(err) {
+			// Only the taints from the environment are removed without the CRD.
+			return nil
+		}
+		checkForbidden(ctx, err, "list", "taintremovers")
+		return err
+	}
+
+	processed := make(map[string]bool, len(report.nodes))
+	for _, n := range report.nodes {
+		processed[n.Name] = true
+	}
+	now := metav1.NewTime(r.currentTime())
+	var errs []error
+	for i := range removers.Items {
+		remover := &removers.Items[i]
+		status := remover.Status.DeepCopy()
+		status.PreviewDiffs = r.previewDiffs(remover, processed, report.previews)
+		status.NoExecuteRemovals = noExecuteEntries(remover, processed, report.noExecute)
+		status.TaintStatuses = r.taintStatuses(remover, report.removals, report.patched, now)
+		status.RemovalCounts = r.addRemovalCounts(remover, report.removals, report.patched)
+		if equality.Semantic.DeepEqual(*status, remover.Status) {
+			continue
+		}
+		remover.Status = *status
+		if err := r.Status().Update(ctx, remover); err != nil {
+			checkForbidden(ctx, err, "update", "taintremovers/status")
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("TaintStatuses", func() {
+	var (
+		ctx     context.Context
+		now     time.Time
+		spot    corev1.Taint
+		absent  corev1.Taint
+		node    *corev1.Node
+		remover *nodesv1alpha1.TaintRemover
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+		spot = corev1.Taint{Key: "cloud.example.com/spot", Effect: corev1.TaintEffectNoSchedule}
+		absent = corev1.Taint{Key: "absent", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{spot}},
+		}
+		remover = &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "spot"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{spot, absent}},
+		}
+	})
+
+	reconcileWith := func(c client.Client) {
+		reconciler := &TaintRemoverReconciler{Client: c, now: func() time.Time { return now }}
+		_, _ = reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(remover), remover)).To(Succeed())
+	}
+
+	It("should record the removed and not present taints", func() {
+		reconcileWith(newFakeClient(node, remover))
+		Expect(remover.Status.TaintStatuses).To(ConsistOf(
+			nodesv1alpha1.TaintStatus{Key: spot.Key, Effect: spot.Effect,
+				Outcome: nodesv1alpha1.TaintOutcomeRemoved, LastTransitionTime: metav1.NewTime(now)},
+			nodesv1alpha1.TaintStatus{Key: absent.Key, Effect: absent.Effect,
+				Outcome: nodesv1alpha1.TaintOutcomeNotPresent, LastTransitionTime: metav1.NewTime(now)},
+		))
+	})
+
+	It("should record the failed taints", func() {
+		reconcileWith(newForbiddenPatchClient(node, remover))
+		Expect(remover.Status.TaintStatuses).To(ContainElement(
+			nodesv1alpha1.TaintStatus{Key: spot.Key, Effect: spot.Effect,
+				Outcome: nodesv1alpha1.TaintOutcomeFailed, LastTransitionTime: metav1.NewTime(now)},
+		))
+	})
+
+	It("should keep the transition time while the outcome is unchanged", func() {
+		earlier := metav1.NewTime(now.Add(-time.Hour))
+		remover.Status.TaintStatuses = []nodesv1alpha1.TaintStatus{
+			{Key: absent.Key, Effect: absent.Effect, Outcome: nodesv1alpha1.TaintOutcomeNotPresent, LastTransitionTime: earlier},
+		}
+		reconcileWith(newFakeClient(node, remover))
+		Expect(remover.Status.TaintStatuses).To(ContainElement(
+			nodesv1alpha1.TaintStatus{Key: absent.Key, Effect: absent.Effect,
+				Outcome: nodesv1alpha1.TaintOutcomeNotPresent, LastTransitionTime: earlier},
+		))
+	})
+
+	It("should keep the outcome when the taint is gone on the next reconcile", func() {
+		node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{Key: "unlisted", Effect: corev1.TaintEffectNoSchedule})
+		c := newFakeClient(node, remover)
+		reconcileWith(c)
+		now = now.Add(time.Minute)
+		reconcileWith(c)
+		Expect(remover.Status.TaintStatuses).To(ContainElement(
+			nodesv1alpha1.TaintStatus{Key: spot.Key, Effect: spot.Effect,
+				Outcome: nodesv1alpha1.TaintOutcomeRemoved, LastTransitionTime: metav1.NewTime(now.Add(-time.Minute))},
+		))
+	})
+
+	It("should keep the outcome on an event for another node", func() {
+		other := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "unlisted", Effect: corev1.TaintEffectNoSchedule}}},
+		}
+		c := newFakeClient(node, other, remover)
+		reconcileWith(c)
+		reconciler := &TaintRemoverReconciler{Client: c, now: func() time.Time { return now }}
+		Expect(reconciler.applyTaintRemoveOnNode(ctx, other)).To(Succeed())
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(remover), remover)).To(Succeed())
+		Expect(remover.Status.TaintStatuses).To(ContainElement(
+			nodesv1alpha1.TaintStatus{Key: spot.Key, Effect: spot.Effect,
+				Outcome: nodesv1alpha1.TaintOutcomeRemoved, LastTransitionTime: metav1.NewTime(now)},
+		))
+	})
+
+	Context("When a sweep changes several status fields", func() {
+		var observer *nodesv1alpha1.TaintRemover
+
+		BeforeEach(func() {
+			noExecute := corev1.Taint{Key: "example.com/evict", Effect: corev1.TaintEffectNoExecute}
+			observed := corev1.Taint{Key: "example.com/observed", Effect: corev1.TaintEffectNoSchedule}
+			node.Spec.Taints = append(node.Spec.Taints, noExecute, observed)
+			remover.Spec.Taints = append(remover.Spec.Taints, noExecute)
+			observer = &nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "observer"},
+				Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{observed}, ObserveOnly: true},
+			}
+		})
+
+		It("should write the status of each remover once", func() {
+			c := &statusCountingClient{Client: newFakeClient(node, remover, observer)}
+			reconcileWith(c)
+			Expect(c.updates.Load()).To(Equal(int32(2)))
+			Expect(remover.Status.NoExecuteRemovals).To(HaveLen(1))
+			Expect(remover.Status.TaintStatuses).To(HaveLen(3))
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(observer), observer)).To(Succeed())
+			Expect(observer.Status.PreviewDiffs).To(HaveLen(1))
+			Expect(observer.Status.TaintStatuses).To(HaveLen(1))
+		})
+
+		It("should not fail the sweep when writing the status fails", func() {
+			c := &statusCountingClient{Client: newFakeClient(node, remover, observer), err: errors.New("status write failed")}
+			reconciler := &TaintRemoverReconciler{Client: c, now: func() time.Time { return now }}
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.updates.Load()).To(Equal(int32(2)))
+
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+			Expect(node.Spec.Taints).NotTo(ContainElement(spot))
+		})
+	})
+
+	Context("RemovalCounts", func() {
+		retaint := func(c client.Client) {
+			current := &corev1.Node{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(node), current)).To(Succeed())
+			current.Spec.Taints = []corev1.Taint{spot}
+			Expect(c.Update(ctx, current)).To(Succeed())
+		}
+
+		It("should accumulate the counts across reconciles", func() {
+			c := newFakeClient(node, remover)
+			reconcileWith(c)
+			Expect(remover.Status.RemovalCounts).To(Equal(map[string]int32{"cloud.example.com/spot:NoSchedule": 1}))
+
+			retaint(c)
+			reconcileWith(c)
+			Expect(remover.Status.RemovalCounts).To(Equal(map[string]int32{"cloud.example.com/spot:NoSchedule": 2}))
+		})
+
+		It("should count a removal once per node", func() {
+			other := node.DeepCopy()
+			other.Name = "other-node"
+			reconcileWith(newFakeClient(node, other, remover))
+			Expect(remover.Status.RemovalCounts).To(Equal(map[string]int32{"cloud.example.com/spot:NoSchedule": 2}))
+		})
+
+		It("should not count the failed removals", func() {
+			reconcileWith(newForbiddenPatchClient(node, remover))
+			Expect(remover.Status.RemovalCounts).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,397 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+	"github.com/norseto/taint-remover/internal/celmatch"
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// protectedTaintPrefixes are the key prefixes of taints managed by Kubernetes
+// itself. They are never removed by a RemoveAll remover.
+var protectedTaintPrefixes = []string{
+	"node.kubernetes.io/",
+	"node.cloudprovider.kubernetes.io/",
+}
+
+// removeTarget represents a taint to be removed along with the restrictions
+// of the TaintRemover that specified it.
+type removeTarget struct {
+	Taint                      corev1.Taint                              `json:"taint"`
+	Sources                    []string                                  `json:"sources,omitempty"`
+	WhenConditionFalse         []corev1.NodeConditionType                `json:"whenConditionFalse,omitempty"`
+	RemovalDelay               *metav1.Duration                          `json:"removalDelay,omitempty"`
+	NodeSelector               *metav1.LabelSelector                     `json:"nodeSelector,omitempty"`
+	OSSelector                 string                                    `json:"osSelector,omitempty"`
+	NodeGroup                  string                                    `json:"nodeGroup,omitempty"`
+	MachineOwner               string                                    `json:"machineOwner,omitempty"`
+	RemoveAll                  bool                                      `json:"removeAll,omitempty"`
+	ExcludeEffects             []corev1.TaintEffect                      `json:"excludeEffects,omitempty"`
+	KeySelector                *nodesv1alpha1.TaintKeySelector           `json:"keySelector,omitempty"`
+	MatchExpression            string                                    `json:"matchExpression,omitempty"`
+	AggressivePreferNoSchedule bool                                      `json:"aggressivePreferNoSchedule,omitempty"`
+	Priority                   int32                                     `json:"priority,omitempty"`
+	WaitForWorkload            *nodesv1alpha1.WorkloadReference          `json:"waitForWorkload,omitempty"`
+	MaintenanceWindow          *nodesv1alpha1.MaintenanceWindowReference `json:"maintenanceWindow,omitempty"`
+	ObserveOnly                bool                                      `json:"observeOnly,omitempty"`
+	MaxNodes                   *int32                                    `json:"maxNodes,omitempty"`
+	Uncordon                   bool                                      `json:"uncordon,omitempty"`
+
+	remover    string
+	selector   labels.Selector
+	matchMode  matchMode
+	keyPattern *regexp.Regexp
+	effectOnly bool
+	program    *celmatch.Program
+	programErr error
+}
+
+// newRemoveTargets creates the remove targets specified by the remover.
+// A RemoveAll remover yields a single target that matches every taint, but
+// only when it has a node selector.
+func newRemoveTargets(remover *nodesv1alpha1.TaintRemover) ([]removeTarget, error) {
+	spec := &remover.Spec
+	base := removeTarget{
+		Sources:                    spec.Sources,
+		WhenConditionFalse:         spec.WhenConditionFalse,
+		RemovalDelay:               spec.RemovalDelay,
+		NodeSelector:               spec.NodeSelector,
+		OSSelector:                 spec.OSSelector,
+		NodeGroup:                  spec.NodeGroup,
+		MachineOwner:               spec.MachineOwner,
+		ExcludeEffects:             spec.ExcludeEffects,
+		AggressivePreferNoSchedule: spec.AggressivePreferNoSchedule,
+		Priority:                   spec.Priority,
+		WaitForWorkload:            spec.WaitForWorkload,
+		MaintenanceWindow:          spec.MaintenanceWindow,
+		ObserveOnly:                spec.ObserveOnly,
+		MaxNodes:                   spec.MaxNodes,
+		Uncordon:                   spec.Uncordon,
+		remover:                    remover.Name,
+	}
+	if spec.NodeSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(spec.NodeSelector)
+		if err != nil {
+			return nil, err
+		}
+		base.selector = selector
+	}
+	if spec.OSSelector != "" {
+		req, err := labels.NewRequirement(corev1.LabelOSStable, selection.Equals, []string{spec.OSSelector})
+		if err != nil {
+			return nil, err
+		}
+		if base.selector == nil {
+			base.selector = labels.Everything()
+		}
+		base.selector = base.selector.Add(*req)
+	}
+
+	var targets []removeTarget
+	if spec.RemoveAll && base.selector != nil && !base.selector.Empty() {
+		target := base
+		target.RemoveAll = true
+		targets = append(targets, target)
+	}
+	if spec.KeySelector != nil {
+		target := base
+		target.KeySelector = spec.KeySelector
+		targets = append(targets, target)
+	}
+	if spec.MatchExpression != "" {
+		// The expression is compiled once per sweep; an invalid one is
+		// reported by validate.
+		target := base
+		target.MatchExpression = spec.MatchExpression
+		target.program, target.programErr = celmatch.Compile(spec.MatchExpression)
+		targets = append(targets, target)
+	}
+	for _, t := range spec.Taints {
+		target := base
+		target.Taint = t
+		targets = append(targets, target)
+	}
+	for _, ke := range spec.TaintKeyEffects {
+		for _, effect := range ke.Effects {
+			target := base
+			target.Taint = corev1.Taint{Key: ke.Key, Effect: effect}
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+// sameRestrictions reports whether both targets have the same restrictions.
+// Targets limited by MaxNodes count against their own remover, so they are
+// the same only when they come from the same remover.
+func (t *removeTarget) sameRestrictions(other *removeTarget) bool {
+	return slices.Equal(t.Sources, other.Sources) &&
+		slices.Equal(t.WhenConditionFalse, other.WhenConditionFalse) &&
+		equality.Semantic.DeepEqual(t.RemovalDelay, other.RemovalDelay) &&
+		equality.Semantic.DeepEqual(t.NodeSelector, other.NodeSelector) &&
+		t.OSSelector == other.OSSelector &&
+		t.NodeGroup == other.NodeGroup &&
+		t.MachineOwner == other.MachineOwner &&
+		t.RemoveAll == other.RemoveAll &&
+		slices.Equal(t.ExcludeEffects, other.ExcludeEffects) &&
+		equality.Semantic.DeepEqual(t.KeySelector, other.KeySelector) &&
+		t.MatchExpression == other.MatchExpression &&
+		t.AggressivePreferNoSchedule == other.AggressivePreferNoSchedule &&
+		equality.Semantic.DeepEqual(t.WaitForWorkload, other.WaitForWorkload) &&
+		equality.Semantic.DeepEqual(t.MaintenanceWindow, other.MaintenanceWindow) &&
+		t.ObserveOnly == other.ObserveOnly &&
+		equality.Semantic.DeepEqual(t.MaxNodes, other.MaxNodes) &&
+		(t.MaxNodes == nil || t.remover == other.remover) &&
+		t.Uncordon == other.Uncordon &&
+		t.matchMode == other.matchMode &&
+		t.effectOnly == other.effectOnly
+}
+
+// validate checks the taint of a listed taint target, the patterns of a
+// key selector target and the expression of a match expression target.
+// RemoveAll targets have nothing to validate, and effect-only targets only
+// their effect.
+func (t *removeTarget) validate() error {
+	if t.RemoveAll {
+		return nil
+	}
+	if t.MatchExpression != "" {
+		return t.programErr
+	}
+	if t.effectOnly {
+		return tutil.ValidateTaintEffect(t.Taint.Effect)
+	}
+	if t.KeySelector != nil {
+		for _, p := range t.KeySelector.MatchPatterns {
+			if _, err := tutil.TaintKeyMatchesPattern("", p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if t.matchMode == matchModePrefix || t.matchMode == matchModeRegex {
+		return t.validateMatch()
+	}
+	return tutil.CheckTaintValidation(t.Taint)
+}
+
+// compareTargets orders targets by descending priority, then by taint key,
+// effect and value.
+func compareTargets(a, b removeTarget) int {
+	return cmp.Or(
+		cmp.Compare(b.Priority, a.Priority),
+		cmp.Compare(a.Taint.Key, b.Taint.Key),
+		cmp.Compare(a.Taint.Effect, b.Taint.Effect),
+		cmp.Compare(a.Taint.Value, b.Taint.Value),
+	)
+}
+
+// selects reports whether the target's node selector selects the node.
+// Every node is selected when the target has no node selector.
+func (t *removeTarget) selects(node *corev1.Node) bool {
+	if t.selector == nil {
+		return true
+	}
+	return t.selector.Matches(labels.Set(node.Labels))
+}
+
+// listed reports whether the target is a listed taint target, rather than a
+// RemoveAll, key selector or match expression one.
+func (t *removeTarget) listed() bool {
+	return !t.RemoveAll && t.KeySelector == nil && t.MatchExpression == ""
+}
+
+// candidates returns the targets for the node taints matched by the target.
+// A listed taint target yields one target for each node taint it matches in
+// its match mode, carrying the node taint key and effect. Keys are compared
+// by keyMatch in the exact and key-only modes.
+// A RemoveAll target yields one target for each unprotected node taint, a
+// key selector target one for each node taint whose key it selects, and a
+// match expression target one for each node taint its expression matches.
+func (t *removeTarget) candidates(node *corev1.Node, keyMatch keyMatcher) []*removeTarget {
+	var result []*removeTarget
+	if t.listed() {
+		for _, nt := range node.Spec.Taints {
+			if !t.matchesTaint(&nt, keyMatch) {
+				continue
+			}
+			candidate := *t
+			candidate.Taint.Key = nt.Key
+			candidate.Taint.Effect = nt.Effect
+			result = append(result, &candidate)
+		}
+		return result
+	}
+
+	for _, nt := range node.Spec.Taints {
+		switch {
+		case t.RemoveAll:
+			if isProtectedTaint(&nt) {
+				continue
+			}
+		case t.KeySelector != nil:
+			if !keySelected(t.KeySelector, nt.Key) {
+				continue
+			}
+		default:
+			if !t.expressionMatches(&nt, node) {
+				continue
+			}
+		}
+		candidate := *t
+		candidate.Taint = nt
+		result = append(result, &candidate)
+	}
+	return result
+}
+
+// expressionMatches reports whether the match expression of the target is
+// true for the node taint. An expression failing to evaluate, e.g. on a
+// missing node label, does not match.
+func (t *removeTarget) expressionMatches(nt *corev1.Taint, node *corev1.Node) bool {
+	if t.program == nil {
+		return false
+	}
+	matched, err := t.program.Match(nt, node)
+	return err == nil && matched
+}
+
+// keyMatcher reports whether a node taint key matches a target taint key.
+type keyMatcher func(nodeKey, targetKey string) bool
+
+// exactKeyMatch matches taint keys exactly.
+func exactKeyMatch(nodeKey, targetKey string) bool {
+	return nodeKey == targetKey
+}
+
+// keySelected reports whether the key selector selects the taint key.
+func keySelected(selector *nodesv1alpha1.TaintKeySelector, key string) bool {
+	if slices.Contains(selector.MatchKeys, key) {
+		return true
+	}
+	for _, p := range selector.MatchPrefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	for _, p := range selector.MatchPatterns {
+		if matched, _ := tutil.TaintKeyMatchesPattern(key, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// effectExcluded reports whether the effect of the target taint is excluded
+// from removal.
+func (t *removeTarget) effectExcluded() bool {
+	return slices.Contains(t.ExcludeEffects, t.Taint.Effect)
+}
+
+// isProtectedTaint checks if the taint is managed by Kubernetes itself.
+func isProtectedTaint(taint *corev1.Taint) bool {
+	for _, p := range protectedTaintPrefixes {
+		if strings.HasPrefix(taint.Key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceAllowed reports whether the taint key starts with one of the allowed
+// source prefixes. Any key is allowed when no sources are specified.
+func (t *removeTarget) sourceAllowed(key string) bool {
+	if len(t.Sources) < 1 {
+		return true
+	}
+	for _, s := range t.Sources {
+		if strings.HasPrefix(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionsCleared reports whether none of the target's conditions is
+// currently True or Unknown on the node. Absent conditions are cleared.
+func (t *removeTarget) conditionsCleared(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if slices.Contains(t.WhenConditionFalse, c.Type) && c.Status != corev1.ConditionFalse {
+			return false
+		}
+	}
+	return true
+}
+
+// targetSetKey identifies the taint of a target, leaving out the value like
+// MatchTaint does.
+type targetSetKey struct {
+	key    string
+	effect corev1.TaintEffect
+}
+
+// targetSet is a set of targets. Targets are equal when their taints match
+// and they have the same restrictions, and only the targets with the same
+// taint key and effect are compared on insertion.
+type targetSet map[targetSetKey][]*removeTarget
+
+// add adds the target unless the set has an equal one, and reports whether
+// it was added.
+func (s targetSet) add(target *removeTarget) bool {
+	k := targetSetKey{key: target.Taint.Key, effect: target.Taint.Effect}
+	for _, t := range s[k] {
+		if t.sameRestrictions(target) {
+			return false
+		}
+	}
+	s[k] = append(s[k], target)
+	return true
+}
+
+// removalFilter decides whether the target taint may be removed from the node.
+type removalFilter func(node *corev1.Node, target *removeTarget) bool
+
+// allowedByFilters reports whether all filters allow removing the target
+// taint from the node.
+func allowedByFilters(node *corev1.Node, target *removeTarget, filters []removalFilter) bool {
+	for _, f := range filters {
+		if !f(node, target) {
+			return false
+		}
+	}
+	return true
+}
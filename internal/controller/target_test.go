@@ -0,0 +1,346 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("NodeSelector", func() {
+	var (
+		ctx         context.Context
+		custom      corev1.Taint
+		other       corev1.Taint
+		protected   corev1.Taint
+		selected    *corev1.Node
+		notSelected *corev1.Node
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		custom = corev1.Taint{Key: "example.com/draining", Effect: corev1.TaintEffectNoSchedule}
+		other = corev1.Taint{Key: "example.com/other", Value: "x", Effect: corev1.TaintEffectNoExecute}
+		protected = corev1.Taint{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule}
+		selected = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "selected", Labels: map[string]string{"pool": "drained"}},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{custom, other, protected}},
+		}
+		notSelected = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "not-selected", Labels: map[string]string{"pool": "general"}},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{custom, other}},
+		}
+	})
+
+	reconcileWith := func(spec nodesv1alpha1.TaintRemoverSpec) client.Client {
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       spec,
+		}
+		c := newFakeClient(selected, notSelected, tr)
+		reconciler := &TaintRemoverReconciler{Client: c, ConfirmNoExecute: true}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: selected.Name}, selected)).To(Succeed())
+		Expect(c.Get(ctx, types.NamespacedName{Name: notSelected.Name}, notSelected)).To(Succeed())
+		return c
+	}
+
+	Context("When removing all taints", func() {
+		It("should remove all unprotected taints only from selected nodes", func() {
+			reconcileWith(nodesv1alpha1.TaintRemoverSpec{
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "drained"}},
+				RemoveAll:    true,
+			})
+			Expect(selected.Spec.Taints).To(Equal([]corev1.Taint{protected}))
+			Expect(notSelected.Spec.Taints).To(HaveLen(2))
+		})
+
+		It("should have no effect without a node selector", func() {
+			reconcileWith(nodesv1alpha1.TaintRemoverSpec{RemoveAll: true})
+			Expect(selected.Spec.Taints).To(HaveLen(3))
+			Expect(notSelected.Spec.Taints).To(HaveLen(2))
+		})
+	})
+
+	Context("When removing listed taints", func() {
+		It("should remove the taints only from selected nodes", func() {
+			reconcileWith(nodesv1alpha1.TaintRemoverSpec{
+				Taints:       []corev1.Taint{custom},
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "drained"}},
+			})
+			Expect(selected.Spec.Taints).To(Equal([]corev1.Taint{other, protected}))
+			Expect(notSelected.Spec.Taints).To(HaveLen(2))
+		})
+	})
+
+	Context("When the node selector is invalid", func() {
+		It("should skip the remover", func() {
+			reconcileWith(nodesv1alpha1.TaintRemoverSpec{
+				Taints: []corev1.Taint{custom},
+				NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "pool", Operator: "Bogus"},
+				}},
+			})
+			Expect(selected.Spec.Taints).To(HaveLen(3))
+			Expect(notSelected.Spec.Taints).To(HaveLen(2))
+		})
+	})
+})
+
+var _ = Describe("KeySelector", func() {
+	It("should remove the taints listed or selected by key", func() {
+		ctx := context.TODO()
+		listed := corev1.Taint{Key: "example.com/draining", Effect: corev1.TaintEffectNoSchedule}
+		selected := corev1.Taint{Key: "spot.example.com/preempted", Effect: corev1.TaintEffectNoExecute}
+		exact := corev1.Taint{Key: "example.com/exact", Effect: corev1.TaintEffectPreferNoSchedule}
+		kept := corev1.Taint{Key: "example.com/kept", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{listed, selected, exact, kept}},
+		}
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				Taints: []corev1.Taint{listed},
+				KeySelector: &nodesv1alpha1.TaintKeySelector{
+					MatchKeys:     []string{exact.Key},
+					MatchPrefixes: []string{"spot.example.com/"},
+				},
+			},
+		}
+		c := newFakeClient(node, tr)
+		reconciler := &TaintRemoverReconciler{Client: c, ConfirmNoExecute: true}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{kept}))
+	})
+})
+
+var _ = Describe("KeySelector patterns", func() {
+	var (
+		ctx           context.Context
+		uninitialized corev1.Taint
+		spot          corev1.Taint
+		kept          corev1.Taint
+		node          *corev1.Node
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		uninitialized = corev1.Taint{Key: "cloud.example.com/uninitialized", Effect: corev1.TaintEffectNoSchedule}
+		spot = corev1.Taint{Key: "spot.example.com/preempted", Effect: corev1.TaintEffectNoSchedule}
+		kept = corev1.Taint{Key: "example.com/kept", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{uninitialized, spot, kept}},
+		}
+	})
+
+	reconcileWith := func(patterns ...string) []corev1.Taint {
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				KeySelector: &nodesv1alpha1.TaintKeySelector{MatchPatterns: patterns},
+			},
+		}
+		c := newFakeClient(node, tr)
+		_, err := (&TaintRemoverReconciler{Client: c}).Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		return node.Spec.Taints
+	}
+
+	It("should remove the taints whose key ends with a suffix", func() {
+		Expect(reconcileWith("*/uninitialized")).To(Equal([]corev1.Taint{spot, kept}))
+	})
+
+	It("should remove the taints whose key starts with a prefix", func() {
+		Expect(reconcileWith("spot.example.com/*")).To(Equal([]corev1.Taint{uninitialized, kept}))
+	})
+
+	It("should skip a selector with a malformed pattern", func() {
+		Expect(reconcileWith("*/uninitialized", "cloud*initialized")).To(HaveLen(3))
+	})
+})
+
+var _ = Describe("TaintKeyEffects", func() {
+	It("should remove the key with every listed effect only", func() {
+		ctx := context.TODO()
+		noSchedule := corev1.Taint{Key: "example.com/draining", Effect: corev1.TaintEffectNoSchedule}
+		noExecute := corev1.Taint{Key: "example.com/draining", Value: "x", Effect: corev1.TaintEffectNoExecute}
+		prefer := corev1.Taint{Key: "example.com/draining", Effect: corev1.TaintEffectPreferNoSchedule}
+		other := corev1.Taint{Key: "example.com/other", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{noSchedule, noExecute, prefer, other}},
+		}
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				TaintKeyEffects: []nodesv1alpha1.TaintKeyEffects{{
+					Key:     "example.com/draining",
+					Effects: []corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectNoExecute},
+				}},
+			},
+		}
+		c := newFakeClient(node, tr)
+		reconciler := &TaintRemoverReconciler{Client: c, ConfirmNoExecute: true}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{prefer, other}))
+	})
+
+	It("should convert each effect into a target", func() {
+		targets, err := newRemoveTargets(&nodesv1alpha1.TaintRemover{
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				TaintKeyEffects: []nodesv1alpha1.TaintKeyEffects{{
+					Key:     "example.com/draining",
+					Effects: []corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule},
+				}},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		var taints []corev1.Taint
+		for _, t := range targets {
+			taints = append(taints, t.Taint)
+		}
+		Expect(taints).To(Equal([]corev1.Taint{
+			{Key: "example.com/draining", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "example.com/draining", Effect: corev1.TaintEffectPreferNoSchedule},
+		}))
+	})
+})
+
+var _ = Describe("getAllRemoveTaints", func() {
+	It("should order the targets by descending priority", func() {
+		soft := corev1.Taint{Key: "a-soft", Effect: corev1.TaintEffectPreferNoSchedule}
+		hard := corev1.Taint{Key: "b-hard", Effect: corev1.TaintEffectNoSchedule}
+		shared := corev1.Taint{Key: "c-shared", Effect: corev1.TaintEffectNoSchedule}
+		removers := []client.Object{
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "a-low"},
+				Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{soft, shared}},
+			},
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "b-high"},
+				Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{hard, shared}, Priority: 10},
+			},
+		}
+		targets, err := getAllRemoveTaints(context.TODO(), newFakeClient(removers...), nil, targetSource{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var keys []string
+		var priorities []int32
+		for _, t := range targets {
+			keys = append(keys, t.Taint.Key)
+			priorities = append(priorities, t.Priority)
+		}
+		Expect(keys).To(Equal([]string{"b-hard", "c-shared", "a-soft"}))
+		Expect(priorities).To(Equal([]int32{10, 10, 0}))
+	})
+
+	It("should order the targets by key, effect and value", func() {
+		removers := []client.Object{
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "a"},
+				Spec: nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{
+					{Key: "zone", Value: "b", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "alpha", Effect: corev1.TaintEffectNoSchedule},
+				}},
+			},
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "b"},
+				Spec: nodesv1alpha1.TaintRemoverSpec{
+					Taints: []corev1.Taint{
+						{Key: "zone", Value: "a", Effect: corev1.TaintEffectNoSchedule},
+						{Key: "alpha", Effect: corev1.TaintEffectNoExecute},
+					},
+					Sources: []string{"zone", "alpha"},
+				},
+			},
+		}
+		targets, err := getAllRemoveTaints(context.TODO(), newFakeClient(removers...), nil, targetSource{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var taints []corev1.Taint
+		for _, t := range targets {
+			taints = append(taints, t.Taint)
+		}
+		Expect(taints).To(Equal([]corev1.Taint{
+			{Key: "alpha", Effect: corev1.TaintEffectNoExecute},
+			{Key: "alpha", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "zone", Value: "a", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "zone", Value: "b", Effect: corev1.TaintEffectNoSchedule},
+		}))
+	})
+})
+
+var _ = Describe("RemoverSelector", func() {
+	It("should only use the taints of matching removers", func() {
+		ctx := context.TODO()
+		labelled := corev1.Taint{Key: "labelled", Effect: corev1.TaintEffectNoSchedule}
+		unlabelled := corev1.Taint{Key: "unlabelled", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{labelled, unlabelled}},
+		}
+		c := newFakeClient(node,
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "labelled", Labels: map[string]string{"env": "prod"}},
+				Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{labelled}},
+			},
+			&nodesv1alpha1.TaintRemover{
+				ObjectMeta: metav1.ObjectMeta{Name: "unlabelled"},
+				Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{unlabelled}},
+			},
+		)
+		reconciler := &TaintRemoverReconciler{
+			Client:          c,
+			RemoverSelector: labels.SelectorFromSet(labels.Set{"env": "prod"}),
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{unlabelled}))
+	})
+})
+
+var _ = Describe("InvalidTaints", func() {
+	It("should skip invalid taints with a warning event", func() {
+		ctx := context.TODO()
+		valid := corev1.Taint{Key: "valid", Effect: corev1.TaintEffectNoSchedule}
+		invalid := corev1.Taint{Key: "bad@key", Effect: corev1.TaintEffectNoSchedule}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{valid, invalid}},
+		}
+		c := newFakeClient(node, &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "mixed"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: []corev1.Taint{valid, invalid}},
+		})
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &TaintRemoverReconciler{Client: c, Recorder: recorder}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{invalid}))
+
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix(corev1.EventTypeWarning+" InvalidTaint"),
+			ContainSubstring("bad@key"),
+		)))
+	})
+})
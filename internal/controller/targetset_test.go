@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// scanTargetExists is the linear scan targetSet replaces, kept as the
+// reference of its behavior.
+func scanTargetExists(targets []removeTarget, targetToFind *removeTarget) bool {
+	for _, t := range targets {
+		if t.Taint.MatchTaint(&targetToFind.Taint) && t.sameRestrictions(targetToFind) {
+			return true
+		}
+	}
+	return false
+}
+
+// manyTargets returns the targets of n removers listing overlapping taints
+// with varied restrictions.
+func manyTargets(n int) []removeTarget {
+	effects := []corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule}
+	maxNodes := int32(1)
+	var targets []removeTarget
+	for i := 0; i < n; i++ {
+		for j := 0; j < 5; j++ {
+			target := removeTarget{
+				Taint: corev1.Taint{
+					Key:    fmt.Sprintf("example.com/taint-%d", (i+j)%(n/2+1)),
+					Value:  fmt.Sprint(j),
+					Effect: effects[j%len(effects)],
+				},
+				NodeGroup: fmt.Sprintf("group-%d", i%3),
+				remover:   fmt.Sprintf("remover-%d", i),
+			}
+			if i%4 == 0 {
+				target.MaxNodes = &maxNodes
+			}
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// manyRemovers returns n removers listing overlapping taints.
+func manyRemovers(n int) []client.Object {
+	var objs []client.Object
+	for i := 0; i < n; i++ {
+		var taints []corev1.Taint
+		for j := 0; j < 5; j++ {
+			taints = append(taints, corev1.Taint{
+				Key:    fmt.Sprintf("example.com/taint-%d", (i+j)%(n/2+1)),
+				Effect: corev1.TaintEffectNoSchedule,
+			})
+		}
+		objs = append(objs, &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("remover-%d", i)},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				Taints:    taints,
+				NodeGroup: fmt.Sprintf("group-%d", i%3),
+			},
+		})
+	}
+	return objs
+}
+
+var _ = Describe("targetSet", func() {
+	It("should keep the same targets as a linear scan", func() {
+		targets := manyTargets(60)
+		var scanned, indexed []removeTarget
+		set := targetSet{}
+		for _, t := range targets {
+			if !scanTargetExists(scanned, &t) {
+				scanned = append(scanned, t)
+			}
+			if set.add(&t) {
+				indexed = append(indexed, t)
+			}
+		}
+		Expect(indexed).To(Equal(scanned))
+		Expect(len(indexed)).To(BeNumerically("<", len(targets)))
+	})
+
+	It("should keep the targets of removers with other restrictions", func() {
+		taint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+		set := targetSet{}
+		Expect(set.add(&removeTarget{Taint: taint})).To(BeTrue())
+		Expect(set.add(&removeTarget{Taint: corev1.Taint{Key: "foo", Value: "bar", Effect: taint.Effect}})).To(BeFalse())
+		Expect(set.add(&removeTarget{Taint: taint, NodeGroup: "gpu"})).To(BeTrue())
+		Expect(set.add(&removeTarget{Taint: corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoExecute}})).To(BeTrue())
+	})
+})
+
+func BenchmarkTargetScan(b *testing.B) {
+	targets := manyTargets(300)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var kept []removeTarget
+		for j := range targets {
+			if !scanTargetExists(kept, &targets[j]) {
+				kept = append(kept, targets[j])
+			}
+		}
+	}
+}
+
+func BenchmarkTargetSet(b *testing.B) {
+	targets := manyTargets(300)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set := targetSet{}
+		for j := range targets {
+			set.add(&targets[j])
+		}
+	}
+}
+
+func BenchmarkGetAllRemoveTaints(b *testing.B) {
+	s := runtime.NewScheme()
+	if err := nodesv1alpha1.AddToScheme(s); err != nil {
+		b.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(manyRemovers(300)...).Build()
+	ctx := context.TODO()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := getAllRemoveTaints(ctx, c, nil, targetSource{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
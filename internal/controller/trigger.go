@@ -0,0 +1,78 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// TriggerReconcile requests a full sweep. It can be called by code embedding
+// the controller and never blocks; a request made while another one is still
+// pending is merged into it.
+func (r *TaintRemoverReconciler) TriggerReconcile() {
+	select {
+	case r.triggerChan() <- event.GenericEvent{Object: &nodesv1alpha1.TaintRemover{}}:
+	default:
+	}
+}
+
+// ReconcileHandler returns an HTTP handler requesting a full sweep on POST.
+func (r *TaintRemoverReconciler) ReconcileHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.TriggerReconcile()
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+// triggerChan returns the channel TriggerReconcile sends requests to.
+func (r *TaintRemoverReconciler) triggerChan() chan event.GenericEvent {
+	r.triggerOnce.Do(func() {
+		r.trigger = make(chan event.GenericEvent, 1)
+	})
+	return r.trigger
+}
+
+// triggerSource returns the source that enqueues a sweep for each request
+// made by TriggerReconcile.
+func (r *TaintRemoverReconciler) triggerSource() source.Source {
+	return source.Channel(r.triggerChan(), handler.EnqueueRequestsFromMapFunc(
+		func(context.Context, client.Object) []reconcile.Request {
+			return []reconcile.Request{{}}
+		}))
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+var _ = Describe("TriggerReconcile", func() {
+	It("should enqueue a sweep", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer queue.ShutDown()
+
+		reconciler := &TaintRemoverReconciler{}
+		Expect(reconciler.triggerSource().Start(ctx, queue)).To(Succeed())
+
+		reconciler.TriggerReconcile()
+		Eventually(queue.Len).Should(Equal(1))
+	})
+
+	It("should not block when a sweep is already pending", func() {
+		reconciler := &TaintRemoverReconciler{}
+		reconciler.TriggerReconcile()
+		reconciler.TriggerReconcile()
+		Expect(reconciler.triggerChan()).To(HaveLen(1))
+	})
+
+	It("should request a sweep on POST", func() {
+		reconciler := &TaintRemoverReconciler{}
+		rec := httptest.NewRecorder()
+		reconciler.ReconcileHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/reconcile", nil))
+		Expect(rec.Code).To(Equal(http.StatusAccepted))
+		Expect(reconciler.triggerChan()).To(HaveLen(1))
+	})
+
+	It("should reject other methods", func() {
+		reconciler := &TaintRemoverReconciler{}
+		rec := httptest.NewRecorder()
+		reconciler.ReconcileHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reconcile", nil))
+		Expect(rec.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(reconciler.triggerChan()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Uncordon", func() {
+	var (
+		taint         corev1.Taint
+		unschedulable corev1.Taint
+		node          *corev1.Node
+	)
+
+	BeforeEach(func() {
+		taint = corev1.Taint{Key: "example.com/not-ready", Effect: corev1.TaintEffectNoSchedule}
+		unschedulable = corev1.Taint{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec: corev1.NodeSpec{
+				Unschedulable: true,
+				Taints:        []corev1.Taint{taint, unschedulable},
+			},
+		}
+	})
+
+	removeWith := func(targets ...*removeTarget) {
+		reconciler := &TaintRemoverReconciler{Client: newFakeClient(node)}
+		_, err := reconciler.removeTaints(context.TODO(), []*corev1.Node{node}, targets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(node), node)).To(Succeed())
+	}
+
+	It("should untaint and uncordon the node when requested", func() {
+		removeWith(&removeTarget{Taint: taint, Uncordon: true}, &removeTarget{Taint: unschedulable, Uncordon: true})
+		Expect(node.Spec.Taints).To(BeEmpty())
+		Expect(node.Spec.Unschedulable).To(BeFalse())
+	})
+
+	It("should only untaint the node otherwise", func() {
+		removeWith(&removeTarget{Taint: taint}, &removeTarget{Taint: unschedulable})
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{unschedulable}))
+		Expect(node.Spec.Unschedulable).To(BeTrue())
+	})
+
+	It("should not uncordon the node when nothing is removed", func() {
+		node.Spec.Taints = []corev1.Taint{unschedulable}
+		removeWith(&removeTarget{Taint: taint, Uncordon: true})
+		Expect(node.Spec.Unschedulable).To(BeTrue())
+	})
+
+	It("should leave the schedulability out of the patch without uncordon", func() {
+		patches := makePatches([]*corev1.Node{node}, []*removeTarget{{Taint: taint}}, metav1.Now().Time, exactKeyMatch)
+		Expect(patches).To(HaveLen(1))
+		Expect(patches[0].patch.Spec.Unschedulable).To(BeNil())
+	})
+})
@@ -0,0 +1,91 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// countMatchedNodes returns the number of nodes each listed taint target
+// matches a taint of. RemoveAll, key selector and match expression targets
+// are not counted.
+func countMatchedNodes(nodes []*corev1.Node, taints []*removeTarget, keyMatch keyMatcher) map[*removeTarget]int {
+	counts := map[*removeTarget]int{}
+	for _, t := range taints {
+		if t == nil || !t.listed() {
+			continue
+		}
+		counts[t] = 0
+		for _, n := range nodes {
+			if t.selects(n) && len(t.candidates(n, keyMatch)) > 0 {
+				counts[t]++
+			}
+		}
+	}
+	return counts
+}
+
+// warnUnmatchedTaints logs the taints listed by the removers that matched no
+// node in the sweep over the nodes, and emits a Warning event on their
+// removers. Nothing is done without WarnUnmatchedTaints.
+func (r *TaintRemoverReconciler) warnUnmatchedTaints(ctx context.Context, nodes []*corev1.Node, taints []*removeTarget) {
+	if !r.WarnUnmatchedTaints {
+		return
+	}
+	logger := log.FromContext(ctx)
+	unmatched := map[string][]corev1.Taint{}
+	counts := countMatchedNodes(nodes, taints, r.keyMatcher())
+	for _, t := range taints {
+		if count, ok := counts[t]; !ok || count > 0 {
+			continue
+		}
+		logger.Info("Listed taint matched no node", "remover", t.remover, "taint", t.Taint.ToString())
+		unmatched[t.remover] = append(unmatched[t.remover], t.Taint)
+	}
+	if len(unmatched) < 1 || r.Recorder == nil {
+		return
+	}
+
+	removers := &nodesv1alpha1.TaintRemoverList{}
+	if err := r.List(ctx, removers, r.removerListOptions()...); err != nil {
+		if !isMissingCRD(err) {
+			checkForbidden(ctx, err, "list", "taintremovers")
+			logger.Error(err, "Failed to report unmatched taints")
+		}
+		return
+	}
+	for i := range removers.Items {
+		remover := &removers.Items[i]
+		for _, t := range unmatched[remover.Name] {
+			r.Recorder.Eventf(remover, corev1.EventTypeWarning, "UnmatchedTaint",
+				"Taint %s matched no node", t.ToString())
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("WarnUnmatchedTaints", func() {
+	var (
+		ctx      context.Context
+		matched  corev1.Taint
+		typo     corev1.Taint
+		node     *corev1.Node
+		recorder *record.FakeRecorder
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		matched = corev1.Taint{Key: "example.com/matched", Effect: corev1.TaintEffectNoSchedule}
+		typo = corev1.Taint{Key: "example.com/mathced", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{matched}},
+		}
+		recorder = record.NewFakeRecorder(10)
+	})
+
+	reconcileWith := func(warn bool, taints ...corev1.Taint) {
+		tr := &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taint-remover"},
+			Spec:       nodesv1alpha1.TaintRemoverSpec{Taints: taints},
+		}
+		reconciler := &TaintRemoverReconciler{
+			Client:              newFakeClient(node, tr),
+			Recorder:            recorder,
+			WarnUnmatchedTaints: warn,
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("should warn about a taint matching no node", func() {
+		reconcileWith(true, matched, typo)
+		Expect(recorder.Events).To(Receive(Equal(corev1.EventTypeWarning +
+			" UnmatchedTaint Taint example.com/mathced:NoSchedule matched no node")))
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeNormal + " TaintsRemoved")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should be silent for a matched taint", func() {
+		reconcileWith(true, matched)
+		Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeNormal + " TaintsRemoved")))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should be silent when disabled", func() {
+		reconcileWith(false, typo)
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should count the nodes matched by each listed taint", func() {
+		other := node.DeepCopy()
+		other.Name = "other-node"
+		targets := []*removeTarget{{Taint: matched}, {Taint: typo}, {Taint: matched, RemoveAll: true}}
+		counts := countMatchedNodes([]*corev1.Node{node, other}, targets, exactKeyMatch)
+		Expect(counts).To(Equal(map[*removeTarget]int{targets[0]: 2, targets[1]: 0}))
+	})
+})
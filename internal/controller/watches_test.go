@@ -0,0 +1,45 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// recordingBuilder records the watches registered on it.
+type recordingBuilder struct {
+	objects    []client.Object
+	rawSources int
+}
+
+func (b *recordingBuilder) Watches(object client.Object, _ handler.EventHandler,
+	_ ...builder.WatchesOption) *builder.Builder {
+	b.objects = append(b.objects, object)
+	return nil
+}
+
+func (b *recordingBuilder) WatchesRawSource(source.Source) *builder.Builder {
+	b.rawSources++
+	return nil
+}
+
+var _ = Describe("addWatches", func() {
+	It("should watch the nodes by default", func() {
+		b := &recordingBuilder{}
+		(&TaintRemoverReconciler{}).addWatches(b)
+		Expect(b.objects).To(ConsistOf(BeAssignableToTypeOf(&corev1.Node{})))
+		Expect(b.rawSources).To(Equal(1))
+	})
+
+	It("should not watch the nodes when disabled", func() {
+		b := &recordingBuilder{}
+		(&TaintRemoverReconciler{DisableNodeWatch: true}).addWatches(b)
+		Expect(b.objects).To(BeEmpty())
+		Expect(b.rawSources).To(Equal(1))
+	})
+})
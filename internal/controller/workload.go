@@ -0,0 +1,90 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get
+
+// gatedRequeue is the interval at which the removals kept until a workload
+// is ready, a maintenance window is active or a PodDisruptionBudget allows
+// them are retried.
+const gatedRequeue = 30 * time.Second
+
+// deploymentAvailable reports whether the referenced Deployment has the
+// Available condition set to True.
+func deploymentAvailable(ctx context.Context, c client.Reader, ref *nodesv1alpha1.WorkloadReference) (bool, error) {
+	deployment := &appsv1.Deployment{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, deployment)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range deployment.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable {
+			return c.Status == corev1.ConditionTrue, nil
+		}
+	}
+	return false, nil
+}
+
+// workloadFilter returns a removal filter that keeps the taints of targets
+// waiting for a Deployment that is not Available yet. Each Deployment is
+// looked up once per sweep, and the sweep is marked for a retry when any
+// removal is kept.
+func (r *TaintRemoverReconciler) workloadFilter(ctx context.Context) removalFilter {
+	logger := log.FromContext(ctx)
+	available := make(map[nodesv1alpha1.WorkloadReference]bool)
+	r.workloadPending.Store(false)
+	return func(_ *corev1.Node, target *removeTarget) bool {
+		ref := target.WaitForWorkload
+		if ref == nil {
+			return true
+		}
+		ready, seen := available[*ref]
+		if !seen {
+			var err error
+			ready, err = deploymentAvailable(ctx, r.liveReader(), ref)
+			if err != nil {
+				logger.Error(err, "Failed to get workload", "namespace", ref.Namespace, "name", ref.Name)
+			}
+			available[*ref] = ready
+		}
+		if !ready {
+			r.workloadPending.Store(true)
+		}
+		return ready
+	}
+}
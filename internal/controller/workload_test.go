@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+var _ = Describe("WaitForWorkload", func() {
+	var (
+		taint   corev1.Taint
+		node    *corev1.Node
+		remover *nodesv1alpha1.TaintRemover
+	)
+
+	BeforeEach(func() {
+		taint = corev1.Taint{Key: "node.example.com/bootstrap", Effect: corev1.TaintEffectNoSchedule}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{taint}},
+		}
+		remover = &nodesv1alpha1.TaintRemover{
+			ObjectMeta: metav1.ObjectMeta{Name: "cni"},
+			Spec: nodesv1alpha1.TaintRemoverSpec{
+				Taints:          []corev1.Taint{taint},
+				WaitForWorkload: &nodesv1alpha1.WorkloadReference{Namespace: "kube-system", Name: "cni"},
+			},
+		}
+	})
+
+	newDeployment := func(status corev1.ConditionStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "cni"},
+			Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: status},
+			}},
+		}
+	}
+
+	It("should keep the taint and requeue while the Deployment is not Available", func() {
+		ctx := context.TODO()
+		reconciler := newFakeReconciler(node, remover, newDeployment(corev1.ConditionFalse))
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(gatedRequeue))
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{taint}))
+	})
+
+	It("should keep the taint while the Deployment does not exist", func() {
+		ctx := context.TODO()
+		reconciler := newFakeReconciler(node, remover)
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(gatedRequeue))
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(Equal([]corev1.Taint{taint}))
+	})
+
+	It("should remove the taint once the Deployment is Available", func() {
+		ctx := context.TODO()
+		reconciler := newFakeReconciler(node, remover, newDeployment(corev1.ConditionTrue))
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: node.Name}, node)).To(Succeed())
+		Expect(node.Spec.Taints).To(BeEmpty())
+	})
+})
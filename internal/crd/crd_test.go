@@ -0,0 +1,73 @@
+package crd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newCRD returns the TaintRemover CRD with the given Established status.
+func newCRD(status apiextensionsv1.ConditionStatus) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: TaintRemoverCRDName},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{
+				{Type: apiextensionsv1.Established, Status: status},
+			},
+		},
+	}
+}
+
+func TestWaitForEstablished(t *testing.T) {
+	// The CRD shows up on the second lookup and gets established on the third.
+	var gets int
+	cs := fake.NewSimpleClientset()
+	cs.PrependReactor("get", "customresourcedefinitions",
+		func(k8stesting.Action) (bool, runtime.Object, error) {
+			gets++
+			switch gets {
+			case 1:
+				return false, nil, nil
+			case 2:
+				return true, newCRD(apiextensionsv1.ConditionFalse), nil
+			default:
+				return true, newCRD(apiextensionsv1.ConditionTrue), nil
+			}
+		})
+
+	err := WaitForEstablished(context.TODO(), cs.ApiextensionsV1().CustomResourceDefinitions(),
+		TaintRemoverCRDName, time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatalf("WaitForEstablished() error = %v", err)
+	}
+	if gets != 3 {
+		t.Errorf("WaitForEstablished() looked up the CRD %d times, want 3", gets)
+	}
+}
+
+func TestWaitForEstablishedTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		objs []runtime.Object
+	}{
+		{name: "missing"},
+		{name: "not established", objs: []runtime.Object{newCRD(apiextensionsv1.ConditionFalse)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset(test.objs...)
+			err := WaitForEstablished(context.TODO(), cs.ApiextensionsV1().CustomResourceDefinitions(),
+				TaintRemoverCRDName, time.Millisecond, 20*time.Millisecond)
+			if err == nil {
+				t.Fatal("WaitForEstablished() expected error, got none")
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package nodetaints dumps, restores and removes the taints of the nodes in a cluster.
+package nodetaints
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	tutil "github.com/norseto/taint-remover/internal/taints"
+)
+
+// NodeTaints maps node names to their taints.
+type NodeTaints map[string][]corev1.Taint
+
+// Collect lists all nodes in the cluster and returns their taints.
+// Nodes without taints are included with an empty list.
+func Collect(ctx context.Context, c client.Reader) (NodeTaints, error) {
+	list := &corev1.NodeList{}
+	err := c.List(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+
+	result := NodeTaints{}
+	for _, v := range list.Items {
+		taints := []corev1.Taint{}
+		result[v.Name] = append(taints, v.Spec.Taints...)
+	}
+	return result, nil
+}
+
+// Write writes the node taints to w as a YAML document.
+func (n NodeTaints) Write(w io.Writer) error {
+	data, err := yaml.Marshal(n)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Read reads node taints from a YAML document.
+func Read(r io.Reader) (NodeTaints, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	result := NodeTaints{}
+	if err := yaml.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// nodeSpecPatch defines the specification for patching a node's taints.
+type nodeSpecPatch struct {
+	Taints []corev1.Taint `json:"taints"`
+}
+
+// nodePatch represents a patch for a node object
+type nodePatch struct {
+	Spec nodeSpecPatch `json:"spec"`
+}
+
+// Restore adds the recorded taints back to the nodes. Taints already on a
+// node are left as they are and no taint is removed. Nodes that no longer
+// exist are skipped with a warning. It returns the number of patched nodes.
+func Restore(ctx context.Context, c client.Client, dump NodeTaints) (int, error) {
+	logger := log.FromContext(ctx)
+	restored := 0
+
+	for _, name := range slices.Sorted(maps.Keys(dump)) {
+		node := &corev1.Node{}
+		err := c.Get(ctx, types.NamespacedName{Name: name}, node)
+		if errors.IsNotFound(err) {
+			logger.Info("Node no longer exists, skipping", "node", name)
+			continue
+		}
+		if err != nil {
+			return restored, err
+		}
+
+		taints := node.Spec.Taints
+		for _, t := range dump[name] {
+			if !tutil.TaintExists(taints, &t) {
+				taints = append(taints, t)
+			}
+		}
+		if len(taints) == len(node.Spec.Taints) {
+			continue
+		}
+
+		data, err := json.Marshal(nodePatch{Spec: nodeSpecPatch{Taints: taints}})
+		if err != nil {
+			return restored, err
+		}
+		logger.Info("Restore node taints", "node", name, "Patch", string(data))
+		err = c.Patch(ctx, node, client.RawPatch(types.StrategicMergePatchType, data))
+		if err != nil {
+			return restored, err
+		}
+		restored++
+	}
+	return restored, nil
+}
+
+// Remove removes the given taints from the named node and returns the taints
+// actually removed. The node is not patched when none of the taints is on it.
+func Remove(ctx context.Context, c client.Client, name string, taints []corev1.Taint) ([]corev1.Taint, error) {
+	node := &corev1.Node{}
+	err := c.Get(ctx, types.NamespacedName{Name: name}, node)
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("node %s not found", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := node.Spec.Taints
+	var removed []corev1.Taint
+	for _, t := range taints {
+		var deleted bool
+		remaining, deleted = tutil.DeleteTaint(remaining, &t)
+		if deleted {
+			removed = append(removed, t)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(nodePatch{Spec: nodeSpecPatch{Taints: remaining}})
+	if err != nil {
+		return nil, err
+	}
+	log.FromContext(ctx).Info("Remove node taints", "node", name, "Patch", string(data))
+	err = c.Patch(ctx, node, client.RawPatch(types.StrategicMergePatchType, data))
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
@@ -0,0 +1,207 @@
+package nodetaints
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+// newFakeClient returns a fake client holding the given nodes.
+func newFakeClient(t *testing.T, nodes ...*corev1.Node) client.Client {
+	s := runtime.NewScheme()
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	builder := fake.NewClientBuilder().WithScheme(s)
+	for _, n := range nodes {
+		builder = builder.WithObjects(n)
+	}
+	return builder.Build()
+}
+
+func newNode(name string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Taints: taints},
+	}
+}
+
+func TestCollect(t *testing.T) {
+	taint := corev1.Taint{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoSchedule}
+	c := newFakeClient(t, newNode("node1", taint), newNode("node2"))
+
+	got, err := Collect(context.TODO(), c)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := NodeTaints{
+		"node1": {taint},
+		"node2": {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Collect() = %v, want %v", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	taint := corev1.Taint{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoSchedule}
+	c := newFakeClient(t, newNode("node1", taint), newNode("node2"))
+
+	dump, err := Collect(context.TODO(), c)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := dump.Write(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := `node1:
+- effect: NoSchedule
+  key: foo
+  value: bar
+node2: []
+`
+	if buf.String() != want {
+		t.Errorf("Write() = %q, want %q", buf.String(), want)
+	}
+
+	var decoded map[string][]map[string]string
+	if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decoded["node1"][0]["key"] != "foo" {
+		t.Errorf("Write() produced unexpected structure: %v", decoded)
+	}
+}
+
+func TestRead(t *testing.T) {
+	input := `node1:
+- effect: NoSchedule
+  key: foo
+  value: bar
+node2: []
+`
+	got, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := NodeTaints{
+		"node1": {{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoSchedule}},
+		"node2": {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %v, want %v", got, want)
+	}
+
+	if _, err := Read(strings.NewReader("- not a map")); err == nil {
+		t.Errorf("Read() expected error, got none")
+	}
+}
+
+func TestRestore(t *testing.T) {
+	foo := corev1.Taint{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoSchedule}
+	baz := corev1.Taint{Key: "baz", Effect: corev1.TaintEffectNoExecute}
+	other := corev1.Taint{Key: "other", Effect: corev1.TaintEffectPreferNoSchedule}
+	c := newFakeClient(t, newNode("node1", other), newNode("node2", foo))
+
+	dump := NodeTaints{
+		"node1": {foo, baz},
+		"node2": {foo},
+		"gone":  {foo},
+	}
+	restored, err := Restore(context.TODO(), c, dump)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if restored != 1 {
+		t.Errorf("Restore() restored = %v, want %v", restored, 1)
+	}
+
+	tests := []struct {
+		node string
+		want []corev1.Taint
+	}{
+		{node: "node1", want: []corev1.Taint{other, foo, baz}},
+		{node: "node2", want: []corev1.Taint{foo}},
+	}
+	for _, test := range tests {
+		node := &corev1.Node{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: test.node}, node); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(node.Spec.Taints, test.want) {
+			t.Errorf("Restore() %s taints = %v, want %v", test.node, node.Spec.Taints, test.want)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	foo := corev1.Taint{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoSchedule}
+	baz := corev1.Taint{Key: "baz", Effect: corev1.TaintEffectNoExecute}
+	other := corev1.Taint{Key: "other", Effect: corev1.TaintEffectPreferNoSchedule}
+
+	tests := []struct {
+		name        string
+		node        string
+		wantRemoved []corev1.Taint
+		wantTaints  []corev1.Taint
+		wantErr     string
+	}{
+		{
+			name:        "matching taints",
+			node:        "tainted",
+			wantRemoved: []corev1.Taint{foo, baz},
+			wantTaints:  []corev1.Taint{other},
+		},
+		{
+			name:       "no matching taints",
+			node:       "untainted",
+			wantTaints: []corev1.Taint{other},
+		},
+		{
+			name:    "missing node",
+			node:    "gone",
+			wantErr: "node gone not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := newFakeClient(t, newNode("tainted", foo, other, baz), newNode("untainted", other))
+			removed, err := Remove(context.TODO(), c, test.node, []corev1.Taint{foo, baz})
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("Remove() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(removed, test.wantRemoved) {
+				t.Errorf("Remove() removed = %v, want %v", removed, test.wantRemoved)
+			}
+
+			node := &corev1.Node{}
+			if err := c.Get(context.TODO(), types.NamespacedName{Name: test.node}, node); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(node.Spec.Taints, test.wantTaints) {
+				t.Errorf("Remove() taints = %v, want %v", node.Spec.Taints, test.wantTaints)
+			}
+		})
+	}
+}
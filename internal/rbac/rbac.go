@@ -0,0 +1,133 @@
+/*
+MIT License
+
+Copyright (c) 2023 Norihiro Seto
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package rbac verifies that the controller has the permissions it needs.
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+
+	nodesv1alpha1 "github.com/norseto/taint-remover/api/v1alpha1"
+)
+
+// Permission describes a verb on a resource required by the controller.
+type Permission struct {
+	Group       string
+	Resource    string
+	Subresource string
+	Verb        string
+}
+
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Subresource != "" {
+		resource += "/" + p.Subresource
+	}
+	if p.Group == "" {
+		return p.Verb + " " + resource
+	}
+	return p.Verb + " " + resource + "." + p.Group
+}
+
+// RequiredPermissions lists the permissions the controller needs to work.
+// Deployments and ConfigMaps are included because any TaintRemover may
+// reference them through waitForWorkload and maintenanceWindow.
+var RequiredPermissions = []Permission{
+	{Resource: "nodes", Verb: "get"},
+	{Resource: "nodes", Verb: "list"},
+	{Resource: "nodes", Verb: "watch"},
+	{Resource: "nodes", Verb: "patch"},
+	{Group: nodesv1alpha1.GroupVersion.Group, Resource: "taintremovers", Verb: "get"},
+	{Group: nodesv1alpha1.GroupVersion.Group, Resource: "taintremovers", Verb: "list"},
+	{Group: nodesv1alpha1.GroupVersion.Group, Resource: "taintremovers", Verb: "watch"},
+	{Group: nodesv1alpha1.GroupVersion.Group, Resource: "taintremovers", Verb: "patch"},
+	{Group: nodesv1alpha1.GroupVersion.Group, Resource: "taintremovers", Subresource: "status", Verb: "update"},
+	{Resource: "events", Verb: "create"},
+	{Group: "apps", Resource: "deployments", Verb: "get"},
+	{Resource: "configmaps", Verb: "get"},
+}
+
+// Options selects the permissions needed only by optional features.
+type Options struct {
+	// ListPods is set when pods on a node are listed, with
+	// --log-affected-pods or --protect-noexecute-with-pods.
+	ListPods bool
+	// RespectPDB is set with --respect-pdb.
+	RespectPDB bool
+	// WaitForCRD is set with --wait-for-crd.
+	WaitForCRD bool
+}
+
+// PermissionsFor returns RequiredPermissions extended with the permissions
+// of the features enabled in o.
+func PermissionsFor(o Options) []Permission {
+	perms := append([]Permission{}, RequiredPermissions...)
+	if o.ListPods || o.RespectPDB {
+		perms = append(perms, Permission{Resource: "pods", Verb: "list"})
+	}
+	if o.RespectPDB {
+		perms = append(perms, Permission{Group: "policy", Resource: "poddisruptionbudgets", Verb: "list"})
+	}
+	if o.WaitForCRD {
+		perms = append(perms, Permission{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions", Verb: "get"})
+	}
+	return perms
+}
+
+// CheckPermissions issues a SelfSubjectAccessReview for each permission and
+// returns an error listing the permissions that are not allowed.
+func CheckPermissions(ctx context.Context, c authorizationv1client.SelfSubjectAccessReviewInterface, perms []Permission) error {
+	var denied []string
+
+	for _, p := range perms {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:       p.Group,
+					Resource:    p.Resource,
+					Subresource: p.Subresource,
+					Verb:        p.Verb,
+				},
+			},
+		}
+		result, err := c.Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to review permission %s: %w", p, err)
+		}
+		if !result.Status.Allowed {
+			denied = append(denied, p.String())
+		}
+	}
+
+	if len(denied) > 0 {
+		return fmt.Errorf("missing permissions: %s", strings.Join(denied, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package rbac
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeClient returns a fake clientset that denies the given verbs on
+// nodes and allows everything else.
+func newFakeClient(deniedVerbs ...string) *fake.Clientset {
+	cs := fake.NewSimpleClientset()
+	cs.PrependReactor("create", "selfsubjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			attrs := review.Spec.ResourceAttributes
+			review.Status.Allowed = true
+			for _, v := range deniedVerbs {
+				if attrs.Resource == "nodes" && attrs.Verb == v {
+					review.Status.Allowed = false
+				}
+			}
+			return true, review, nil
+		})
+	return cs
+}
+
+func TestCheckPermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		deniedVerbs []string
+		expectError bool
+		wantMessage string
+	}{
+		{
+			name:        "all permissions allowed",
+			expectError: false,
+		},
+		{
+			name:        "patch on nodes denied",
+			deniedVerbs: []string{"patch"},
+			expectError: true,
+			wantMessage: "patch nodes",
+		},
+		{
+			name:        "watch and patch on nodes denied",
+			deniedVerbs: []string{"watch", "patch"},
+			expectError: true,
+			wantMessage: "watch nodes, patch nodes",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cs := newFakeClient(test.deniedVerbs...)
+			err := CheckPermissions(context.TODO(), cs.AuthorizationV1().SelfSubjectAccessReviews(), RequiredPermissions)
+			if test.expectError && err == nil {
+				t.Errorf("CheckPermissions() expected error, got none")
+			} else if !test.expectError && err != nil {
+				t.Errorf("CheckPermissions() returned unexpected error: %v", err)
+			} else if err != nil && !strings.Contains(err.Error(), test.wantMessage) {
+				t.Errorf("CheckPermissions() error = %v, want it to contain %q", err, test.wantMessage)
+			}
+		})
+	}
+}
+
+func TestPermissionString(t *testing.T) {
+	tests := []struct {
+		perm Permission
+		want string
+	}{
+		{perm: Permission{Resource: "nodes", Verb: "patch"}, want: "patch nodes"},
+		{perm: Permission{Group: "nodes.peppy-ratio.dev", Resource: "taintremovers", Verb: "list"},
+			want: "list taintremovers.nodes.peppy-ratio.dev"},
+		{perm: Permission{Group: "nodes.peppy-ratio.dev", Resource: "taintremovers", Subresource: "status", Verb: "update"},
+			want: "update taintremovers/status.nodes.peppy-ratio.dev"},
+	}
+
+	for _, test := range tests {
+		if got := test.perm.String(); got != test.want {
+			t.Errorf("Permission.String() = %v, want %v", got, test.want)
+		}
+	}
+}
+
+func TestPermissionsFor(t *testing.T) {
+	pods := Permission{Resource: "pods", Verb: "list"}
+	pdbs := Permission{Group: "policy", Resource: "poddisruptionbudgets", Verb: "list"}
+	crds := Permission{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions", Verb: "get"}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		want    []Permission
+		notWant []Permission
+	}{
+		{
+			name:    "no optional features",
+			notWant: []Permission{pods, pdbs, crds},
+		},
+		{
+			name:    "pods listed",
+			opts:    Options{ListPods: true},
+			want:    []Permission{pods},
+			notWant: []Permission{pdbs, crds},
+		},
+		{
+			name:    "respect PDB",
+			opts:    Options{RespectPDB: true},
+			want:    []Permission{pods, pdbs},
+			notWant: []Permission{crds},
+		},
+		{
+			name: "all features",
+			opts: Options{ListPods: true, RespectPDB: true, WaitForCRD: true},
+			want: []Permission{pods, pdbs, crds},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := PermissionsFor(test.opts)
+			count := func(p Permission) int {
+				n := 0
+				for _, g := range got {
+					if g == p {
+						n++
+					}
+				}
+				return n
+			}
+			for _, p := range RequiredPermissions {
+				if count(p) != 1 {
+					t.Errorf("PermissionsFor() missing required permission %s", p)
+				}
+			}
+			for _, p := range test.want {
+				if count(p) != 1 {
+					t.Errorf("PermissionsFor() want %s once, got %d", p, count(p))
+				}
+			}
+			for _, p := range test.notWant {
+				if count(p) != 0 {
+					t.Errorf("PermissionsFor() should not contain %s", p)
+				}
+			}
+		})
+	}
+}
@@ -18,7 +18,9 @@ limitations under the License.
 package taints
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
@@ -32,10 +34,45 @@ const (
 	UNTAINTED = "untainted"
 )
 
+// Errors returned for invalid taint specs, wrapped with the spec and, when
+// known, the error of the offending part.
+var (
+	ErrInvalidTaintSpec   = errors.New("invalid taint spec")
+	ErrInvalidTaintEffect = errors.New("invalid taint effect")
+	ErrDuplicateTaint     = errors.New("duplicated taints with the same key and effect")
+)
+
+// ParseOption changes how taint specs are parsed.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	decodeValues bool
+}
+
+// DecodeValues URL-decodes the values of the taint specs before validating
+// them, so that key=a%2Eb:NoSchedule yields the value a.b. Values are taken
+// literally by default. A decoded value must still be a valid label value:
+// key=a%2Fb:NoSchedule decodes to a/b and is rejected, since taint values
+// cannot hold a '/'.
+func DecodeValues() ParseOption {
+	return func(o *parseOptions) {
+		o.decodeValues = true
+	}
+}
+
+func newParseOptions(opts []ParseOption) parseOptions {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // parseTaint parses a taint from a string, whose form must be either
 // '<key>=<value>:<effect>', '<key>:<effect>', or '<key>'.
-func parseTaint(st string) (v1.Taint, error) {
+func parseTaint(st string, opts ...ParseOption) (v1.Taint, error) {
 	var taint v1.Taint
+	o := newParseOptions(opts)
 
 	var key string
 	var value string
@@ -47,27 +84,34 @@ func parseTaint(st string) (v1.Taint, error) {
 		key = parts[0]
 	case 2:
 		effect = v1.TaintEffect(parts[1])
-		if err := validateTaintEffect(effect); err != nil {
+		if err := ValidateTaintEffect(effect); err != nil {
 			return taint, err
 		}
 
 		partsKV := strings.Split(parts[0], "=")
 		if len(partsKV) > 2 {
-			return taint, fmt.Errorf("invalid taint spec: %v", st)
+			return taint, fmt.Errorf("%w: %v", ErrInvalidTaintSpec, st)
 		}
 		key = partsKV[0]
 		if len(partsKV) == 2 {
 			value = partsKV[1]
+			if o.decodeValues {
+				decoded, err := url.PathUnescape(value)
+				if err != nil {
+					return taint, fmt.Errorf("%w: %v, %w: %v", ErrInvalidTaintSpec, st, ErrTaintValue, err)
+				}
+				value = decoded
+			}
 			if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
-				return taint, fmt.Errorf("invalid taint spec: %v, %s", st, strings.Join(errs, "; "))
+				return taint, fmt.Errorf("%w: %v, %w: %s", ErrInvalidTaintSpec, st, ErrTaintValue, strings.Join(errs, "; "))
 			}
 		}
 	default:
-		return taint, fmt.Errorf("invalid taint spec: %v", st)
+		return taint, fmt.Errorf("%w: %v", ErrInvalidTaintSpec, st)
 	}
 
-	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
-		return taint, fmt.Errorf("invalid taint spec: %v, %s", st, strings.Join(errs, "; "))
+	if err := checkTaintKey(key); err != nil {
+		return taint, fmt.Errorf("%w: %v, %w", ErrInvalidTaintSpec, st, err)
 	}
 
 	taint.Key = key
@@ -77,9 +121,10 @@ func parseTaint(st string) (v1.Taint, error) {
 	return taint, nil
 }
 
-func validateTaintEffect(effect v1.TaintEffect) error {
+// ValidateTaintEffect checks the taint effect is one of the supported ones.
+func ValidateTaintEffect(effect v1.TaintEffect) error {
 	if effect != v1.TaintEffectNoSchedule && effect != v1.TaintEffectPreferNoSchedule && effect != v1.TaintEffectNoExecute {
-		return fmt.Errorf("invalid taint effect: %v, unsupported taint effect", effect)
+		return fmt.Errorf("%w: %v, unsupported taint effect", ErrInvalidTaintEffect, effect)
 	}
 
 	return nil
@@ -87,29 +132,30 @@ func validateTaintEffect(effect v1.TaintEffect) error {
 
 // ParseTaints takes a spec which is an array and creates slices for new taints to be added, taints to be deleted.
 // It also validates the spec. For example, the form `<key>` may be used to remove a taint, but not to add one.
-func ParseTaints(spec []string) ([]v1.Taint, []v1.Taint, error) {
+// The values are taken literally unless DecodeValues is given.
+func ParseTaints(spec []string, opts ...ParseOption) ([]v1.Taint, []v1.Taint, error) {
 	var taints, taintsToRemove []v1.Taint
 	uniqueTaints := map[v1.TaintEffect]sets.String{}
 
 	for _, taintSpec := range spec {
 		if strings.HasSuffix(taintSpec, "-") {
-			taintToRemove, err := parseTaint(strings.TrimSuffix(taintSpec, "-"))
+			taintToRemove, err := parseTaint(strings.TrimSuffix(taintSpec, "-"), opts...)
 			if err != nil {
 				return nil, nil, err
 			}
 			taintsToRemove = append(taintsToRemove, v1.Taint{Key: taintToRemove.Key, Effect: taintToRemove.Effect})
 		} else {
-			newTaint, err := parseTaint(taintSpec)
+			newTaint, err := parseTaint(taintSpec, opts...)
 			if err != nil {
 				return nil, nil, err
 			}
 			// validate that the taint has an effect, which is required to add the taint
 			if len(newTaint.Effect) == 0 {
-				return nil, nil, fmt.Errorf("invalid taint spec: %v", taintSpec)
+				return nil, nil, fmt.Errorf("%w: %v", ErrInvalidTaintSpec, taintSpec)
 			}
 			// validate if taint is unique by <key, effect>
 			if len(uniqueTaints[newTaint.Effect]) > 0 && uniqueTaints[newTaint.Effect].Has(newTaint.Key) {
-				return nil, nil, fmt.Errorf("duplicated taints with the same key and effect: %v", newTaint)
+				return nil, nil, fmt.Errorf("%w: %v", ErrDuplicateTaint, newTaint)
 			}
 			// add taint to existingTaints for uniqueness check
 			if len(uniqueTaints[newTaint.Effect]) == 0 {
@@ -123,6 +169,50 @@ func ParseTaints(spec []string) ([]v1.Taint, []v1.Taint, error) {
 	return taints, taintsToRemove, nil
 }
 
+// TaintSpecError reports an invalid taint spec by its index in the
+// validated specs. It wraps the error of the spec.
+type TaintSpecError struct {
+	Index int
+	Spec  string
+	Err   error
+}
+
+func (e *TaintSpecError) Error() string {
+	return fmt.Sprintf("taint spec %d %q: %v", e.Index, e.Spec, e.Err)
+}
+
+func (e *TaintSpecError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateTaints validates the specs of taints to add, in the forms accepted
+// by ParseTaints, and returns an error for each invalid spec. Unlike
+// ParseTaints, it does not stop at the first invalid spec.
+func ValidateTaints(spec []string, opts ...ParseOption) []*TaintSpecError {
+	var errs []*TaintSpecError
+	seen := sets.New[string]()
+	for i, taintSpec := range spec {
+		taint, err := parseTaint(taintSpec, opts...)
+		if err == nil && len(taint.Effect) == 0 {
+			err = fmt.Errorf("%w: %v, effect is required", ErrInvalidTaintSpec, taintSpec)
+		}
+		if err == nil {
+			err = CheckTaintValidation(taint)
+		}
+		if err == nil {
+			key := taint.Key + ":" + string(taint.Effect)
+			if seen.Has(key) {
+				err = fmt.Errorf("%w: %v", ErrDuplicateTaint, taint.ToString())
+			}
+			seen.Insert(key)
+		}
+		if err != nil {
+			errs = append(errs, &TaintSpecError{Index: i, Spec: taintSpec, Err: err})
+		}
+	}
+	return errs
+}
+
 // CheckIfTaintsAlreadyExists checks if the node already has taints that we want to add and returns a string with taint keys.
 func CheckIfTaintsAlreadyExists(oldTaints []v1.Taint, taints []v1.Taint) string {
 	var existingTaintList = make([]string, 0)
@@ -150,12 +240,22 @@ func DeleteTaintsByKey(taints []v1.Taint, taintKey string) ([]v1.Taint, bool) {
 	return newTaints, deleted
 }
 
+// withoutTimeAdded returns a copy of the taint with TimeAdded cleared, so that
+// it is never taken into account when comparing taints.
+func withoutTimeAdded(taint *v1.Taint) *v1.Taint {
+	t := *taint
+	t.TimeAdded = nil
+	return &t
+}
+
 // DeleteTaint removes all the taints that have the same key and effect to given taintToDelete.
+// TimeAdded is ignored when comparing taints.
 func DeleteTaint(taints []v1.Taint, taintToDelete *v1.Taint) ([]v1.Taint, bool) {
 	newTaints := []v1.Taint{}
 	deleted := false
+	normalized := withoutTimeAdded(taintToDelete)
 	for i := range taints {
-		if taintToDelete.MatchTaint(&taints[i]) {
+		if normalized.MatchTaint(withoutTimeAdded(&taints[i])) {
 			deleted = true
 			continue
 		}
@@ -183,15 +283,48 @@ func RemoveTaint(node *v1.Node, taint *v1.Taint) (*v1.Node, bool, error) {
 }
 
 // TaintExists checks if the given taint exists in list of taints. Returns true if exists false otherwise.
+// TimeAdded is ignored when comparing taints.
 func TaintExists(taints []v1.Taint, taintToFind *v1.Taint) bool {
+	normalized := withoutTimeAdded(taintToFind)
 	for _, taint := range taints {
-		if taint.MatchTaint(taintToFind) {
+		if withoutTimeAdded(&taint).MatchTaint(normalized) {
 			return true
 		}
 	}
 	return false
 }
 
+// taintIndexKey identifies a taint in a TaintIndex. Like MatchTaint, it
+// leaves out the value, so that a lookup agrees with TaintExists.
+type taintIndexKey struct {
+	key    string
+	effect v1.TaintEffect
+}
+
+// TaintIndex is a set of taints with constant time membership checks, for
+// when TaintExists would be called on the same taints in a loop.
+type TaintIndex map[taintIndexKey]bool
+
+// NewTaintIndex returns an index of the taints.
+func NewTaintIndex(taints []v1.Taint) TaintIndex {
+	index := make(TaintIndex, len(taints))
+	for i := range taints {
+		index[taintIndexKey{key: taints[i].Key, effect: taints[i].Effect}] = true
+	}
+	return index
+}
+
+// Contains reports whether the index has a taint with the same key and
+// effect as the given taint, as TaintExists does.
+func (i TaintIndex) Contains(taint *v1.Taint) bool {
+	return i[taintIndexKey{key: taint.Key, effect: taint.Effect}]
+}
+
+// Add adds the taint to the index.
+func (i TaintIndex) Add(taint *v1.Taint) {
+	i[taintIndexKey{key: taint.Key, effect: taint.Effect}] = true
+}
+
 // TaintKeyExists checks if the given taint key exists in list of taints. Returns true if exists false otherwise.
 func TaintKeyExists(taints []v1.Taint, taintKeyToMatch string) bool {
 	for _, taint := range taints {
@@ -208,15 +341,50 @@ func TaintKeyExists(taints []v1.Taint, taintKeyToMatch string) bool {
 // input: taintsNew=[a b] taintsOld=[a c]
 // output: taintsToAdd=[b] taintsToRemove=[c]
 func TaintSetDiff(taintsNew, taintsOld []v1.Taint) (taintsToAdd []*v1.Taint, taintsToRemove []*v1.Taint) {
+	return taintSetDiffBy(taintsNew, taintsOld, func(t *v1.Taint) taintIndexKey {
+		return taintIndexKey{key: t.Key, effect: t.Effect}
+	})
+}
+
+// taintKeyValue identifies a taint by its key and value, leaving out the effect.
+type taintKeyValue struct {
+	key   string
+	value string
+}
+
+// TaintSetDiffKeyValue is like TaintSetDiff, but compares the taints on
+// their key and value only. A taint whose effect alone changed is neither
+// added nor removed, for tooling that manages taints by identity.
+// for example:
+// input: taintsNew=[a=1:NoExecute b=1:NoSchedule] taintsOld=[a=1:NoSchedule b=2:NoSchedule]
+// output: taintsToAdd=[b=1:NoSchedule] taintsToRemove=[b=2:NoSchedule]
+func TaintSetDiffKeyValue(taintsNew, taintsOld []v1.Taint) (taintsToAdd []*v1.Taint, taintsToRemove []*v1.Taint) {
+	return taintSetDiffBy(taintsNew, taintsOld, func(t *v1.Taint) taintKeyValue {
+		return taintKeyValue{key: t.Key, value: t.Value}
+	})
+}
+
+// taintSetDiffBy finds the difference between two taint slices, comparing
+// the taints on the identity returned by id.
+func taintSetDiffBy[K comparable](taintsNew, taintsOld []v1.Taint, id func(*v1.Taint) K) (
+	taintsToAdd []*v1.Taint, taintsToRemove []*v1.Taint) {
+	oldIndex := make(map[K]bool, len(taintsOld))
+	for i := range taintsOld {
+		oldIndex[id(&taintsOld[i])] = true
+	}
 	for _, taint := range taintsNew {
-		if !TaintExists(taintsOld, &taint) {
+		if !oldIndex[id(&taint)] {
 			t := taint
 			taintsToAdd = append(taintsToAdd, &t)
 		}
 	}
 
+	newIndex := make(map[K]bool, len(taintsNew))
+	for i := range taintsNew {
+		newIndex[id(&taintsNew[i])] = true
+	}
 	for _, taint := range taintsOld {
-		if !TaintExists(taintsNew, &taint) {
+		if !newIndex[id(&taint)] {
 			t := taint
 			taintsToRemove = append(taintsToRemove, &t)
 		}
@@ -225,6 +393,38 @@ func TaintSetDiff(taintsNew, taintsOld []v1.Taint) (taintsToAdd []*v1.Taint, tai
 	return
 }
 
+// TaintSetsEqual reports whether both taint slices hold the same taints in
+// any order. Taints are compared as by TaintExists.
+func TaintSetsEqual(a, b []v1.Taint) bool {
+	toAdd, toRemove := TaintSetDiff(a, b)
+	return len(toAdd) == 0 && len(toRemove) == 0
+}
+
+// TaintSlicesEqualUnordered reports whether both taint slices hold the same
+// taints in any order, counting duplicates. Unlike TaintSetsEqual, the values
+// are compared too; TimeAdded is ignored.
+func TaintSlicesEqualUnordered(a, b []v1.Taint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	type taintID struct {
+		key, value string
+		effect     v1.TaintEffect
+	}
+	counts := make(map[taintID]int, len(a))
+	for _, t := range a {
+		counts[taintID{key: t.Key, value: t.Value, effect: t.Effect}]++
+	}
+	for _, t := range b {
+		id := taintID{key: t.Key, value: t.Value, effect: t.Effect}
+		if counts[id] == 0 {
+			return false
+		}
+		counts[id]--
+	}
+	return true
+}
+
 // TaintSetFilter filters from the taint slice according to the passed fn function to get the filtered taint slice.
 func TaintSetFilter(taints []v1.Taint, fn func(*v1.Taint) bool) []v1.Taint {
 	res := []v1.Taint{}
@@ -238,22 +438,108 @@ func TaintSetFilter(taints []v1.Taint, fn func(*v1.Taint) bool) []v1.Taint {
 	return res
 }
 
+// Errors returned by CheckTaintValidation, wrapped with the details of the
+// violation.
+var (
+	ErrTaintKeyTooLong   = errors.New("taint key too long")
+	ErrTaintKeyPrefix    = errors.New("invalid taint key prefix")
+	ErrTaintKeyName      = errors.New("invalid taint key name")
+	ErrTaintValueTooLong = errors.New("taint value too long")
+	ErrTaintValue        = errors.New("invalid taint value")
+)
+
+// ErrTaintKeyPattern is returned by TaintKeyMatchesPattern for patterns
+// other than "prefix*" and "*suffix".
+var ErrTaintKeyPattern = errors.New("invalid taint key pattern")
+
+// TaintKeyMatchesPattern reports whether the taint key matches the pattern.
+// A trailing '*' matches the keys starting with the rest of the pattern, e.g.
+// "example.com/*", and a leading '*' the keys ending with it, e.g.
+// "*/uninitialized". Patterns with another '*', such as "prefix*suffix", or
+// without anything but '*' are rejected.
+func TaintKeyMatchesPattern(key, pattern string) (bool, error) {
+	if strings.Count(pattern, "*") != 1 || len(pattern) < 2 {
+		return false, fmt.Errorf("%w: %q", ErrTaintKeyPattern, pattern)
+	}
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(key, suffix), nil
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(key, prefix), nil
+	}
+	return false, fmt.Errorf("%w: %q", ErrTaintKeyPattern, pattern)
+}
+
+// maxTaintKeyNameLength is the maximum length of the name part of a taint
+// key, as for any qualified name.
+const maxTaintKeyNameLength = 63
+
+// checkTaintKey checks the taint key is a qualified name: an optional DNS
+// subdomain prefix of at most 253 characters and a slash, followed by a name
+// of at most 63 characters.
+func checkTaintKey(key string) error {
+	name := key
+	if i := strings.Index(key, "/"); i >= 0 {
+		var prefix string
+		prefix, name = key[:i], key[i+1:]
+		if len(prefix) > validation.DNS1123SubdomainMaxLength {
+			return fmt.Errorf("%w: prefix must be no more than %d characters",
+				ErrTaintKeyTooLong, validation.DNS1123SubdomainMaxLength)
+		}
+		if errs := validation.IsDNS1123Subdomain(prefix); len(errs) > 0 {
+			return fmt.Errorf("%w: %s", ErrTaintKeyPrefix, strings.Join(errs, "; "))
+		}
+	}
+	if len(name) > maxTaintKeyNameLength {
+		return fmt.Errorf("%w: name must be no more than %d characters",
+			ErrTaintKeyTooLong, maxTaintKeyNameLength)
+	}
+	if strings.Contains(name, "/") {
+		return fmt.Errorf("%w: must contain at most one '/'", ErrTaintKeyName)
+	}
+	if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrTaintKeyName, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
 // CheckTaintValidation checks if the given taint is valid.
 // Returns error if the given taint is invalid.
 func CheckTaintValidation(taint v1.Taint) error {
-	if errs := validation.IsQualifiedName(taint.Key); len(errs) > 0 {
-		return fmt.Errorf("invalid taint key: %s", strings.Join(errs, "; "))
+	if err := checkTaintKey(taint.Key); err != nil {
+		return err
+	}
+	if len(taint.Value) > validation.LabelValueMaxLength {
+		return fmt.Errorf("%w: must be no more than %d characters",
+			ErrTaintValueTooLong, validation.LabelValueMaxLength)
 	}
 	if taint.Value != "" {
 		if errs := validation.IsValidLabelValue(taint.Value); len(errs) > 0 {
-			return fmt.Errorf("invalid taint value: %s", strings.Join(errs, "; "))
+			return fmt.Errorf("%w: %s", ErrTaintValue, strings.Join(errs, "; "))
 		}
 	}
 	if taint.Effect != "" {
-		if err := validateTaintEffect(taint.Effect); err != nil {
+		if err := ValidateTaintEffect(taint.Effect); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// NormalizeTaint returns a copy of the given taint with surrounding whitespace
+// trimmed from its key and value, and its effect canonicalized regardless of
+// casing, e.g. "noschedule" becomes "NoSchedule". Unknown effects are kept.
+func NormalizeTaint(taint v1.Taint) v1.Taint {
+	taint.Key = strings.TrimSpace(taint.Key)
+	taint.Value = strings.TrimSpace(taint.Value)
+	effect := strings.TrimSpace(string(taint.Effect))
+	for _, e := range []v1.TaintEffect{v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute} {
+		if strings.EqualFold(effect, string(e)) {
+			effect = string(e)
+			break
+		}
+	}
+	taint.Effect = v1.TaintEffect(effect)
+	return taint
+}
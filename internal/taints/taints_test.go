@@ -1,9 +1,13 @@
 package taints
 
 import (
+	"errors"
+	"fmt"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 )
@@ -103,6 +107,64 @@ func TestParseTaints(t *testing.T) {
 	}
 }
 
+func TestParseTaintsDecodeValues(t *testing.T) {
+	tests := []struct {
+		name        string
+		taintSpecs  []string
+		opts        []ParseOption
+		expected    []v1.Taint
+		expectedErr error
+	}{
+		{
+			name:       "encoded value decoded",
+			taintSpecs: []string{"key=a%2Eb:NoSchedule"},
+			opts:       []ParseOption{DecodeValues()},
+			expected:   []v1.Taint{{Key: "key", Value: "a.b", Effect: v1.TaintEffectNoSchedule}},
+		},
+		{
+			name:       "literal value decoded unchanged",
+			taintSpecs: []string{"key=a.b:NoSchedule"},
+			opts:       []ParseOption{DecodeValues()},
+			expected:   []v1.Taint{{Key: "key", Value: "a.b", Effect: v1.TaintEffectNoSchedule}},
+		},
+		{
+			name:        "encoded value literal by default",
+			taintSpecs:  []string{"key=a%2Eb:NoSchedule"},
+			expectedErr: ErrTaintValue,
+		},
+		{
+			name:        "decoded value still validated",
+			taintSpecs:  []string{"key=a%2Fb:NoSchedule"},
+			opts:        []ParseOption{DecodeValues()},
+			expectedErr: ErrTaintValue,
+		},
+		{
+			name:        "malformed escape",
+			taintSpecs:  []string{"key=a%zzb:NoSchedule"},
+			opts:        []ParseOption{DecodeValues()},
+			expectedErr: ErrTaintValue,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			taints, _, err := ParseTaints(test.taintSpecs, test.opts...)
+			if test.expectedErr != nil {
+				if !errors.Is(err, test.expectedErr) {
+					t.Errorf("ParseTaints(%v) error = %v, want %v", test.taintSpecs, err, test.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTaints(%v) returned unexpected error: %v", test.taintSpecs, err)
+			}
+			if !reflect.DeepEqual(taints, test.expected) {
+				t.Errorf("ParseTaints(%v) returned incorrect taints, got: %v, want: %v", test.taintSpecs, taints, test.expected)
+			}
+		})
+	}
+}
+
 func TestCheckIfTaintsAlreadyExists(t *testing.T) {
 	existingTaint1 := v1.Taint{Key: "taint1", Effect: v1.TaintEffectNoSchedule}
 	existingTaint2 := v1.Taint{Key: "taint2", Effect: v1.TaintEffectNoExecute}
@@ -173,6 +235,32 @@ func TestDeleteTaint(t *testing.T) {
 	}
 }
 
+func TestTimeAddedIgnored(t *testing.T) {
+	earlier := metav1.NewTime(metav1.Now().Add(-time.Hour))
+	later := metav1.Now()
+	taints := []v1.Taint{
+		{Key: "taint1", Effect: v1.TaintEffectNoExecute, TimeAdded: &earlier},
+		{Key: "taint2", Effect: v1.TaintEffectNoSchedule},
+	}
+	taint := v1.Taint{Key: "taint1", Effect: v1.TaintEffectNoExecute, TimeAdded: &later}
+
+	if !TaintExists(taints, &taint) {
+		t.Errorf("TaintExists() = false, want true for taints differing only by TimeAdded")
+	}
+
+	gotTaints, gotDeleted := DeleteTaint(taints, &taint)
+	wantTaints := []v1.Taint{{Key: "taint2", Effect: v1.TaintEffectNoSchedule}}
+	if !gotDeleted {
+		t.Errorf("DeleteTaint() gotDeleted = false, want true for taints differing only by TimeAdded")
+	}
+	if !reflect.DeepEqual(gotTaints, wantTaints) {
+		t.Errorf("DeleteTaint() gotTaints = %v, want %v", gotTaints, wantTaints)
+	}
+	if taint.TimeAdded != &later {
+		t.Errorf("DeleteTaint() modified the given taint")
+	}
+}
+
 func TestRemoveTaint(t *testing.T) {
 	taint := &v1.Taint{
 		Key:    "foo",
@@ -286,6 +374,64 @@ func TestTaintSetDiff(t *testing.T) {
 	}
 }
 
+func TestTaintSetDiffKeyValue(t *testing.T) {
+	noSchedule := v1.Taint{Key: "taint1", Value: "a", Effect: v1.TaintEffectNoSchedule}
+	noExecute := v1.Taint{Key: "taint1", Value: "a", Effect: v1.TaintEffectNoExecute}
+	otherValue := v1.Taint{Key: "taint1", Value: "b", Effect: v1.TaintEffectNoSchedule}
+	other := v1.Taint{Key: "taint2", Effect: v1.TaintEffectNoSchedule}
+
+	tests := []struct {
+		name           string
+		taintsNew      []v1.Taint
+		taintsOld      []v1.Taint
+		wantToAdd      []*v1.Taint
+		wantToRemove   []*v1.Taint
+		wantEffectAdd  []*v1.Taint
+		wantEffectDrop []*v1.Taint
+	}{
+		{
+			name:           "effect change",
+			taintsNew:      []v1.Taint{noExecute},
+			taintsOld:      []v1.Taint{noSchedule},
+			wantEffectAdd:  []*v1.Taint{&noExecute},
+			wantEffectDrop: []*v1.Taint{&noSchedule},
+		},
+		{
+			name:         "value change",
+			taintsNew:    []v1.Taint{otherValue},
+			taintsOld:    []v1.Taint{noSchedule},
+			wantToAdd:    []*v1.Taint{&otherValue},
+			wantToRemove: []*v1.Taint{&noSchedule},
+		},
+		{
+			name:           "added and removed taints",
+			taintsNew:      []v1.Taint{noExecute, other},
+			taintsOld:      []v1.Taint{noSchedule},
+			wantToAdd:      []*v1.Taint{&other},
+			wantEffectAdd:  []*v1.Taint{&noExecute, &other},
+			wantEffectDrop: []*v1.Taint{&noSchedule},
+		},
+		{
+			name:      "same taints",
+			taintsNew: []v1.Taint{noSchedule, other},
+			taintsOld: []v1.Taint{other, noSchedule},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotToAdd, gotToRemove := TaintSetDiffKeyValue(test.taintsNew, test.taintsOld)
+			if !reflect.DeepEqual(gotToAdd, test.wantToAdd) || !reflect.DeepEqual(gotToRemove, test.wantToRemove) {
+				t.Errorf("TaintSetDiffKeyValue() = %v, %v, want %v, %v", gotToAdd, gotToRemove, test.wantToAdd, test.wantToRemove)
+			}
+			gotToAdd, gotToRemove = TaintSetDiff(test.taintsNew, test.taintsOld)
+			if !reflect.DeepEqual(gotToAdd, test.wantEffectAdd) || !reflect.DeepEqual(gotToRemove, test.wantEffectDrop) {
+				t.Errorf("TaintSetDiff() = %v, %v, want %v, %v", gotToAdd, gotToRemove, test.wantEffectAdd, test.wantEffectDrop)
+			}
+		})
+	}
+}
+
 func TestTaintSetFilter(t *testing.T) {
 	taints := []v1.Taint{
 		{Key: "taint1", Effect: "NoSchedule"},
@@ -342,3 +488,293 @@ func TestCheckTaintValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeTaint(t *testing.T) {
+	tests := []struct {
+		name  string
+		taint v1.Taint
+		want  v1.Taint
+	}{
+		{
+			name:  "canonical taint",
+			taint: v1.Taint{Key: "foo", Value: "bar", Effect: v1.TaintEffectNoSchedule},
+			want:  v1.Taint{Key: "foo", Value: "bar", Effect: v1.TaintEffectNoSchedule},
+		},
+		{
+			name:  "lowercased effect",
+			taint: v1.Taint{Key: "foo", Effect: "noschedule"},
+			want:  v1.Taint{Key: "foo", Effect: v1.TaintEffectNoSchedule},
+		},
+		{
+			name:  "mixed case effect with whitespace",
+			taint: v1.Taint{Key: "foo", Effect: " preferNOschedule "},
+			want:  v1.Taint{Key: "foo", Effect: v1.TaintEffectPreferNoSchedule},
+		},
+		{
+			name:  "whitespace around key and value",
+			taint: v1.Taint{Key: " foo\t", Value: " bar ", Effect: v1.TaintEffectNoExecute},
+			want:  v1.Taint{Key: "foo", Value: "bar", Effect: v1.TaintEffectNoExecute},
+		},
+		{
+			name:  "unknown effect",
+			taint: v1.Taint{Key: "foo", Effect: "Bogus"},
+			want:  v1.Taint{Key: "foo", Effect: "Bogus"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := NormalizeTaint(test.taint); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("NormalizeTaint() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTaintIndex(t *testing.T) {
+	taints := []v1.Taint{{Key: "taint1", Value: "a", Effect: "NoSchedule"}, {Key: "taint2", Effect: "NoExecute"}}
+	index := NewTaintIndex(taints)
+
+	tests := []struct {
+		name  string
+		taint v1.Taint
+		want  bool
+	}{
+		{name: "same taint", taint: v1.Taint{Key: "taint1", Value: "a", Effect: "NoSchedule"}, want: true},
+		{name: "other value", taint: v1.Taint{Key: "taint1", Value: "b", Effect: "NoSchedule"}, want: true},
+		{name: "other effect", taint: v1.Taint{Key: "taint2", Effect: "NoSchedule"}, want: false},
+		{name: "other key", taint: v1.Taint{Key: "taint3", Effect: "NoSchedule"}, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := index.Contains(&test.taint); got != test.want {
+				t.Errorf("Contains() = %v, want %v", got, test.want)
+			}
+			if got := TaintExists(taints, &test.taint); got != test.want {
+				t.Errorf("TaintExists() = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	t.Run("add", func(t *testing.T) {
+		index := TaintIndex{}
+		index.Add(&v1.Taint{Key: "taint3", Value: "a", Effect: "NoSchedule"})
+		if !index.Contains(&v1.Taint{Key: "taint3", Effect: "NoSchedule"}) {
+			t.Errorf("Contains() = false after Add(), want true")
+		}
+	})
+}
+
+// benchmarkTaints returns n distinct taints.
+func benchmarkTaints(n int) []v1.Taint {
+	taints := make([]v1.Taint, n)
+	for i := range taints {
+		taints[i] = v1.Taint{Key: fmt.Sprintf("example.com/taint-%d", i), Effect: v1.TaintEffectNoSchedule}
+	}
+	return taints
+}
+
+func BenchmarkTaintExists(b *testing.B) {
+	taints := benchmarkTaints(200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range taints {
+			TaintExists(taints, &taints[j])
+		}
+	}
+}
+
+func BenchmarkTaintIndexContains(b *testing.B) {
+	taints := benchmarkTaints(200)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		index := NewTaintIndex(taints)
+		for j := range taints {
+			index.Contains(&taints[j])
+		}
+	}
+}
+
+func TestCheckTaintValidationLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		taint v1.Taint
+		want  error
+	}{
+		{
+			name:  "longest valid key and value",
+			taint: v1.Taint{Key: strings.Repeat("a", 253) + "/" + strings.Repeat("b", 63), Value: strings.Repeat("c", 63)},
+		},
+		{
+			name:  "prefix too long",
+			taint: v1.Taint{Key: strings.Repeat("a", 254) + "/key"},
+			want:  ErrTaintKeyTooLong,
+		},
+		{
+			name:  "name too long",
+			taint: v1.Taint{Key: "example.com/" + strings.Repeat("b", 64)},
+			want:  ErrTaintKeyTooLong,
+		},
+		{
+			name:  "invalid prefix",
+			taint: v1.Taint{Key: "Example_com/key"},
+			want:  ErrTaintKeyPrefix,
+		},
+		{
+			name:  "empty prefix",
+			taint: v1.Taint{Key: "/key"},
+			want:  ErrTaintKeyPrefix,
+		},
+		{
+			name:  "invalid name",
+			taint: v1.Taint{Key: "example.com/bad@key"},
+			want:  ErrTaintKeyName,
+		},
+		{
+			name:  "several slashes",
+			taint: v1.Taint{Key: "example.com/a/b"},
+			want:  ErrTaintKeyName,
+		},
+		{
+			name:  "value too long",
+			taint: v1.Taint{Key: "key", Value: strings.Repeat("c", 64)},
+			want:  ErrTaintValueTooLong,
+		},
+		{
+			name:  "invalid value",
+			taint: v1.Taint{Key: "key", Value: "bad value"},
+			want:  ErrTaintValue,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckTaintValidation(test.taint)
+			if test.want == nil && err != nil {
+				t.Errorf("CheckTaintValidation() unexpected error = %v", err)
+			}
+			if test.want != nil && !errors.Is(err, test.want) {
+				t.Errorf("CheckTaintValidation() error = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestTaintSetsEqual(t *testing.T) {
+	a := v1.Taint{Key: "taint1", Effect: "NoSchedule"}
+	b := v1.Taint{Key: "taint2", Effect: "NoExecute"}
+
+	tests := []struct {
+		name string
+		x, y []v1.Taint
+		want bool
+	}{
+		{name: "both empty", want: true},
+		{name: "same order", x: []v1.Taint{a, b}, y: []v1.Taint{a, b}, want: true},
+		{name: "other order", x: []v1.Taint{a, b}, y: []v1.Taint{b, a}, want: true},
+		{name: "missing taint", x: []v1.Taint{a, b}, y: []v1.Taint{a}, want: false},
+		{name: "other taint", x: []v1.Taint{a}, y: []v1.Taint{b}, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := TaintSetsEqual(test.x, test.y); got != test.want {
+				t.Errorf("TaintSetsEqual() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTaintSlicesEqualUnordered(t *testing.T) {
+	a := v1.Taint{Key: "taint1", Value: "v1", Effect: "NoSchedule"}
+	b := v1.Taint{Key: "taint2", Effect: "NoExecute"}
+	aTimed := *a.DeepCopy()
+	aTimed.TimeAdded = &metav1.Time{Time: time.Now()}
+	aOtherValue := v1.Taint{Key: "taint1", Value: "v2", Effect: "NoSchedule"}
+
+	tests := []struct {
+		name string
+		x, y []v1.Taint
+		want bool
+	}{
+		{name: "both empty", want: true},
+		{name: "same order", x: []v1.Taint{a, b}, y: []v1.Taint{a, b}, want: true},
+		{name: "reordered", x: []v1.Taint{a, b}, y: []v1.Taint{b, a}, want: true},
+		{name: "time added ignored", x: []v1.Taint{a, b}, y: []v1.Taint{b, aTimed}, want: true},
+		{name: "other value", x: []v1.Taint{a}, y: []v1.Taint{aOtherValue}, want: false},
+		{name: "differing lengths", x: []v1.Taint{a, b}, y: []v1.Taint{a}, want: false},
+		{name: "same duplicates", x: []v1.Taint{a, a, b}, y: []v1.Taint{a, b, a}, want: true},
+		{name: "other duplicates", x: []v1.Taint{a, a, b}, y: []v1.Taint{a, b, b}, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := TaintSlicesEqualUnordered(test.x, test.y); got != test.want {
+				t.Errorf("TaintSlicesEqualUnordered() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTaintKeyMatchesPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		pattern string
+		want    bool
+		wantErr bool
+	}{
+		{name: "suffix", key: "example.com/uninitialized", pattern: "*/uninitialized", want: true},
+		{name: "suffix mismatch", key: "example.com/initialized", pattern: "*/uninitialized", want: false},
+		{name: "prefix", key: "example.com/uninitialized", pattern: "example.com/*", want: true},
+		{name: "prefix mismatch", key: "other.com/uninitialized", pattern: "example.com/*", want: false},
+		{name: "prefix and suffix", key: "example.com/uninitialized", pattern: "example.com*initialized", wantErr: true},
+		{name: "both ends", key: "example.com/uninitialized", pattern: "*example*", wantErr: true},
+		{name: "star only", key: "example.com/uninitialized", pattern: "*", wantErr: true},
+		{name: "no star", key: "example.com/uninitialized", pattern: "example.com/uninitialized", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := TaintKeyMatchesPattern(test.key, test.pattern)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("TaintKeyMatchesPattern() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr && !errors.Is(err, ErrTaintKeyPattern) {
+				t.Errorf("TaintKeyMatchesPattern() error = %v, want %v", err, ErrTaintKeyPattern)
+			}
+			if got != test.want {
+				t.Errorf("TaintKeyMatchesPattern() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateTaints(t *testing.T) {
+	specs := []string{"foo:NoSchedule", "foo=bar:Bogus", "foo:NoSchedule", "example.com/" + strings.Repeat("a", 64) + ":NoExecute", "foo"}
+	errs := ValidateTaints(specs)
+	want := []struct {
+		index int
+		err   error
+	}{
+		{1, ErrInvalidTaintEffect},
+		{2, ErrDuplicateTaint},
+		{3, ErrTaintKeyTooLong},
+		{4, ErrInvalidTaintSpec},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("ValidateTaints() = %v, want %d errors", errs, len(want))
+	}
+	for i, w := range want {
+		if errs[i].Index != w.index || errs[i].Spec != specs[w.index] {
+			t.Errorf("error %d = %d %q, want %d %q", i, errs[i].Index, errs[i].Spec, w.index, specs[w.index])
+		}
+		if !errors.Is(errs[i], w.err) {
+			t.Errorf("error %d = %v, want %v", i, errs[i], w.err)
+		}
+	}
+	if errs := ValidateTaints(specs[:1]); len(errs) != 0 {
+		t.Errorf("ValidateTaints() = %v, want none", errs)
+	}
+}